@@ -358,7 +358,8 @@ func buildProto(fopts *descriptor.FileOptions, sopts *descriptor.ServiceOptions,
 	return f.AsFileDescriptorProto()
 }
 
-// common loads the common descriptor dependencies for the config annotations.
+// common loads the common descriptor dependencies for the config annotations,
+// along with everything they import.
 func common() []*descriptor.FileDescriptorProto {
 	protoDesc, err := desc.LoadMessageDescriptorForMessage(&descriptor.FileDescriptorProto{})
 	if err != nil {
@@ -415,19 +416,45 @@ func common() []*descriptor.FileDescriptorProto {
 		log.Fatal(err)
 	}
 
-	return []*descriptor.FileDescriptorProto{
-		protoDesc.GetFile().AsFileDescriptorProto(),
-		annoDesc.GetFile().AsFileDescriptorProto(),
-		httpDesc.GetFile().AsFileDescriptorProto(),
-		anyDesc.GetFile().AsFileDescriptorProto(),
-		emptyDesc.GetFile().AsFileDescriptorProto(),
-		statusDesc.GetFile().AsFileDescriptorProto(),
-		clientDesc.GetFile().AsFileDescriptorProto(),
-		resDesc.GetFile().AsFileDescriptorProto(),
-		fieldBehavDesc.GetFile().AsFileDescriptorProto(),
-		lroDesc.GetFile().AsFileDescriptorProto(),
-		durDesc.GetFile().AsFileDescriptorProto(),
+	return closure(
+		protoDesc.GetFile(),
+		annoDesc.GetFile(),
+		httpDesc.GetFile(),
+		anyDesc.GetFile(),
+		emptyDesc.GetFile(),
+		statusDesc.GetFile(),
+		clientDesc.GetFile(),
+		resDesc.GetFile(),
+		fieldBehavDesc.GetFile(),
+		lroDesc.GetFile(),
+		durDesc.GetFile(),
+	)
+}
+
+// closure returns the given files and all of their transitive imports, each
+// file following its dependencies, as required of a CodeGeneratorRequest.
+func closure(files ...*desc.FileDescriptor) []*descriptor.FileDescriptorProto {
+	var protos []*descriptor.FileDescriptorProto
+	seen := map[string]bool{}
+
+	var add func(f *desc.FileDescriptor)
+	add = func(f *desc.FileDescriptor) {
+		if seen[f.GetName()] {
+			return
+		}
+		seen[f.GetName()] = true
+
+		for _, dep := range f.GetDependencies() {
+			add(dep)
+		}
+		protos = append(protos, f.AsFileDescriptorProto())
 	}
+
+	for _, f := range files {
+		add(f)
+	}
+
+	return protos
 }
 
 // lcsDiff is copied from github.com/googleapis/gapic-generator-go/internal/txtdiff .
@@ -15,16 +15,38 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
 	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
 	"github.com/googleapis/gapic-config-validator/internal/validator"
 )
 
+var (
+	descSet string
+	changed string
+	opts    string
+)
+
+func init() {
+	flag.StringVar(&descSet, "descriptor_set_in", "", "validate the FileDescriptorSet, written with protoc --include_imports, instead of running as a protoc plugin")
+	flag.StringVar(&changed, "changed", "", "comma-delimited list of changed paths; read one per line from stdin, e.g. git diff --name-only, if empty")
+	flag.StringVar(&opts, "plugin_opts", "", "comma-delimited list of options for the validator")
+}
+
 func main() {
+	// protoc invokes plugins without arguments
+	if len(os.Args) > 1 {
+		flag.Parse()
+		os.Exit(changedOnly())
+	}
+
 	reqBytes, err := ioutil.ReadAll(os.Stdin)
 	if err != nil {
 		log.Fatal(err)
@@ -48,3 +70,53 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// changedOnly validates the files in the descriptor set that changed, or
+// depend on a changed file, resolving against the entire set. It returns
+// the process exit code.
+func changedOnly() int {
+	if descSet == "" {
+		log.Fatalln("missing required flag -descriptor_set_in")
+	}
+
+	b, err := ioutil.ReadFile(descSet)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fds := &descriptor.FileDescriptorSet{}
+	if err := proto.Unmarshal(b, fds); err != nil {
+		log.Fatal(err)
+	}
+
+	var paths []string
+	if changed != "" {
+		for _, p := range strings.Split(changed, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				paths = append(paths, p)
+			}
+		}
+	} else if paths, err = validator.ReadChangedFiles(os.Stdin); err != nil {
+		log.Fatal(err)
+	}
+
+	req := &plugin.CodeGeneratorRequest{
+		ProtoFile: fds.GetFile(),
+		Parameter: proto.String(opts),
+	}
+	for _, f := range fds.GetFile() {
+		req.FileToGenerate = append(req.FileToGenerate, f.GetName())
+	}
+	validator.RestrictToChanged(req, paths)
+
+	resp, err := validator.Validate(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if e := strings.TrimSpace(resp.GetError()); e != "" {
+		fmt.Fprintln(os.Stderr, e)
+		return 1
+	}
+
+	return 0
+}
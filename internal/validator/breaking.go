@@ -0,0 +1,232 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/longrunning"
+)
+
+// loadBaseline reads the FileDescriptorSet at path, as written by
+// protoc --include_imports --descriptor_set_out, describing the previous
+// version of the API.
+func loadBaseline(path string) (map[string]*desc.FileDescriptor, error) {
+	f, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading baseline descriptor set: %v", err)
+	}
+
+	fds := &descriptor.FileDescriptorSet{}
+	if err := proto.Unmarshal(f, fds); err != nil {
+		return nil, fmt.Errorf("error decoding baseline descriptor set: %v", err)
+	}
+
+	files, err := desc.CreateFileDescriptors(fds.GetFile())
+	if err != nil {
+		return nil, fmt.Errorf("error loading baseline descriptor set: %v", err)
+	}
+
+	return files, nil
+}
+
+// baselineValidator returns a validator over the baseline file set, shared
+// by every validateBreaking call so that its index is built once.
+func (v *validator) baselineValidator() *validator {
+	if v.old == nil {
+		v.old = &validator{files: v.baseline}
+	}
+
+	return v.old
+}
+
+// validateBreaking compares the services, messages and resources defined in
+// the given file to their baseline counterparts, matched by fully-qualified
+// name, and reports annotation changes that break generated clients even
+// when the wire format is compatible.
+func (v *validator) validateBreaking(file *desc.FileDescriptor) {
+	old := v.baselineValidator()
+
+	for _, serv := range file.GetServices() {
+		oldServ := old.resolveServiceByName(serv.GetFullyQualifiedName())
+		if oldServ == nil {
+			continue
+		}
+
+		oldHost, _ := ext(oldServ.GetServiceOptions(), annotations.E_DefaultHost)
+		newHost, _ := ext(serv.GetServiceOptions(), annotations.E_DefaultHost)
+		if o, n := strExt(oldHost), strExt(newHost); o != n {
+			v.addError(brkDefaultHost, serv.GetFullyQualifiedName(), o, n)
+		}
+
+		for _, method := range serv.GetMethods() {
+			if oldMethod := oldServ.FindMethodByName(method.GetName()); oldMethod != nil {
+				v.validateBreakingMethod(old, oldMethod, method)
+			}
+		}
+	}
+
+	msgs := append([]*desc.MessageDescriptor(nil), file.GetMessageTypes()...)
+	for i := 0; i < len(msgs); i++ {
+		msg := msgs[i]
+		msgs = append(msgs, msg.GetNestedMessageTypes()...)
+
+		if oldMsg := old.findMessage(msg.GetFullyQualifiedName()); oldMsg != nil {
+			v.validateBreakingMessage(oldMsg, msg)
+		}
+	}
+
+	for _, d := range v.resourceDefs() {
+		if d.file != file {
+			continue
+		}
+
+		if oldDef, ok := old.index().byType[d.res.GetType()]; ok {
+			v.validateBreakingResource(oldDef.res, d.res)
+		}
+	}
+}
+
+// validateBreakingMethod reports removed or reordered method_signature
+// entries and changed operation_info types.
+func (v *validator) validateBreakingMethod(old *validator, oldMethod, method *desc.MethodDescriptor) {
+	mFQN := method.GetFullyQualifiedName()
+
+	var oldSigs, sigs []string
+	if eSig, err := ext(oldMethod.GetMethodOptions(), annotations.E_MethodSignature); err == nil {
+		oldSigs = eSig.([]string)
+	}
+	if eSig, err := ext(method.GetMethodOptions(), annotations.E_MethodSignature); err == nil {
+		sigs = eSig.([]string)
+	}
+
+	// the surviving signatures must keep their relative order
+	last, lastSig := -1, ""
+	for _, sig := range oldSigs {
+		ndx := indexStr(sigs, sig)
+		if ndx < 0 {
+			v.addError(brkSigRemoved, mFQN, sig)
+			continue
+		}
+
+		if ndx < last {
+			v.addError(brkSigReordered, mFQN, sig, lastSig)
+			continue
+		}
+		last, lastSig = ndx, sig
+	}
+
+	eOld, err := ext(oldMethod.GetMethodOptions(), longrunning.E_OperationInfo)
+	if err != nil {
+		return
+	}
+	oldLRO := eOld.(*longrunning.OperationInfo)
+
+	var lro *longrunning.OperationInfo
+	if eLRO, err := ext(method.GetMethodOptions(), longrunning.E_OperationInfo); err == nil {
+		lro = eLRO.(*longrunning.OperationInfo)
+	}
+
+	for _, t := range []struct {
+		field, old, new string
+	}{
+		{"response_type", oldLRO.GetResponseType(), lro.GetResponseType()},
+		{"metadata_type", oldLRO.GetMetadataType(), lro.GetMetadataType()},
+	} {
+		o := old.operationTypeName(t.old, oldMethod.GetFile())
+		n := v.operationTypeName(t.new, method.GetFile())
+		if o != n {
+			v.addError(brkLROType, mFQN, t.field, o, n)
+		}
+	}
+}
+
+// validateBreakingMessage reports a changed resource type on a resource
+// message and existing fields that became REQUIRED.
+func (v *validator) validateBreakingMessage(oldMsg, msg *desc.MessageDescriptor) {
+	fqn := msg.GetFullyQualifiedName()
+
+	if eOld, err := ext(oldMsg.GetMessageOptions(), annotations.E_Resource); err == nil {
+		oldType := eOld.(*annotations.ResourceDescriptor).GetType()
+
+		var typ string
+		if eRes, err := ext(msg.GetMessageOptions(), annotations.E_Resource); err == nil {
+			typ = eRes.(*annotations.ResourceDescriptor).GetType()
+		}
+
+		if typ != oldType {
+			v.addError(brkResType, fqn, oldType, typ)
+		}
+	}
+
+	for _, field := range msg.GetFields() {
+		oldField := oldMsg.FindFieldByName(field.GetName())
+		if oldField == nil {
+			continue
+		}
+
+		if isRequired(field) && !isRequired(oldField) {
+			v.addError(brkRequired, field.GetFullyQualifiedName())
+		}
+	}
+}
+
+// validateBreakingResource reports removed patterns and a changed name_field
+// on a resource.
+func (v *validator) validateBreakingResource(oldRes, res *annotations.ResourceDescriptor) {
+	typ := res.GetType()
+
+	for _, pat := range oldRes.GetPattern() {
+		if !containStr(res.GetPattern(), pat) {
+			v.addError(brkResPattern, typ, pat)
+		}
+	}
+
+	oldName, name := oldRes.GetNameField(), res.GetNameField()
+	if oldName == "" {
+		oldName = "name"
+	}
+	if name == "" {
+		name = "name"
+	}
+
+	if oldName != name {
+		v.addError(brkNameField, typ, oldName, name)
+	}
+}
+
+// operationTypeName returns the fully-qualified name of the operation_info
+// type, or the type as written if it does not resolve.
+func (v *validator) operationTypeName(name string, file *desc.FileDescriptor) string {
+	if msg, _ := v.resolveMsgReference(name, file); msg != nil {
+		return msg.GetFullyQualifiedName()
+	}
+
+	return name
+}
+
+// strExt dereferences a string extension value, if set.
+func strExt(e interface{}) string {
+	if s, ok := e.(*string); ok && s != nil {
+		return *s
+	}
+
+	return ""
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+)
+
+// ReadChangedFiles reads a newline-delimited list of changed paths, e.g. the
+// output of git diff --name-only, skipping blank lines. The result is
+// non-nil even if nothing changed.
+func ReadChangedFiles(r io.Reader) ([]string, error) {
+	changed := []string{}
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		if p := strings.TrimSpace(s.Text()); p != "" {
+			changed = append(changed, p)
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("error reading changed files: %v", err)
+	}
+
+	return changed, nil
+}
+
+// loadChangedFiles reads the list of changed paths at p.
+func loadChangedFiles(p string) ([]string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("error reading changed files: %v", err)
+	}
+	defer f.Close()
+
+	return ReadChangedFiles(f)
+}
+
+// RestrictToChanged limits the request's FileToGenerate to the files that
+// changed, or that transitively import a changed file. All of the request's
+// ProtoFile remain available for resolution.
+func RestrictToChanged(req *plugin.CodeGeneratorRequest, changed []string) {
+	req.FileToGenerate = filesToValidate(req.GetProtoFile(), req.GetFileToGenerate(), changed)
+}
+
+// filesToValidate returns the files in gen, in order, that are named by a
+// changed path or depend on one through the import graph of protos.
+//
+// Changed paths are typically relative to the repository root rather than
+// the proto import root, so a path matches a proto if it is the proto's name
+// or ends with "/" followed by the name.
+func filesToValidate(protos []*descriptor.FileDescriptorProto, gen, changed []string) []string {
+	importers := map[string][]string{}
+	var queue []string
+	for _, fdp := range protos {
+		name := fdp.GetName()
+		for _, dep := range fdp.GetDependency() {
+			importers[dep] = append(importers[dep], name)
+		}
+
+		for _, c := range changed {
+			if c = path.Clean(c); c == name || strings.HasSuffix(c, "/"+name) {
+				queue = append(queue, name)
+				break
+			}
+		}
+	}
+
+	affected := map[string]bool{}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		if affected[name] {
+			continue
+		}
+		affected[name] = true
+
+		queue = append(queue, importers[name]...)
+	}
+
+	var files []string
+	for _, name := range gen {
+		if affected[name] {
+			files = append(files, name)
+		}
+	}
+
+	return files
+}
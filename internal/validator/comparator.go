@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
-	"os"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/ghodss/yaml"
 	"github.com/golang/protobuf/jsonpb"
@@ -16,29 +16,6 @@ import (
 	"google.golang.org/genproto/googleapis/longrunning"
 )
 
-var (
-	wellKnownPatterns = map[string]bool{
-		"projects/{project}":                      true,
-		"organizations/{organization}":            true,
-		"folders/{folder}":                        true,
-		"projects/{project}/locations/{location}": true,
-		"billingAccounts/{billing_account_id}":    true,
-	}
-
-	wellKnownNames = map[string]bool{
-		"project":      true,
-		"organization": true,
-		"folder":       true,
-		"location":     true,
-	}
-
-	isIamMethod = map[string]bool{
-		"GetIamPolicy":       true,
-		"SetIamPolicy":       true,
-		"TestIamPermissions": true,
-	}
-)
-
 func (v *validator) compare() {
 	// compare interfaces
 	v.compareServices()
@@ -76,7 +53,7 @@ func (v *validator) compareMethod(methodDesc *desc.MethodDescriptor, method *con
 	mOpts := methodDesc.GetMethodOptions()
 
 	// ignore IAM methods
-	if isIamMethod[method.GetName()] {
+	if v.isMixinMethod("google.iam.v1.IAMPolicy", method.GetName()) {
 		return
 	}
 
@@ -175,7 +152,7 @@ func (v *validator) compareResources(inter *config.InterfaceConfigProto) {
 	for _, res := range inter.GetCollections() {
 		ent := snakeToCamel(res.GetEntityName())
 		pat := res.GetNamePattern()
-		if wellKnownPatterns[pat] || wellKnownNames[ent] {
+		if v.isWellKnownPattern(pat) || v.isWellKnownName(res.GetEntityName()) {
 			continue
 		}
 
@@ -266,7 +243,7 @@ func (v *validator) compareResourceRefs() {
 
 			field := msgDesc.FindFieldByName(fname)
 			if field == nil {
-				fmt.Fprintf(os.Stderr, "WARNING: Field %q does not exist on message %q per resource_name_generation item\n", fname, msgDesc.GetFullyQualifiedName())
+				v.addWarning("Field %q does not exist on message %q per resource_name_generation item", fname, msgDesc.GetFullyQualifiedName())
 				continue
 			}
 
@@ -317,7 +294,7 @@ func (v *validator) compareResourceRefs() {
 
 			// compare using upper camel case names
 			t := typ[strings.Index(typ, "/")+1:]
-			if !wellKnownTypes[typ] && t != snakeToCamel(ref) {
+			if !v.isWellKnownType(typ) && t != snakeToCamel(ref) {
 				v.addError("Field %q resource_type_kind %q doesn't match %q in config", field.GetFullyQualifiedName(), typ, ref)
 			}
 		}
@@ -334,6 +311,13 @@ func containBehavior(arr []annotations.FieldBehavior, behv annotations.FieldBeha
 	return false
 }
 
+// isRequired reports if the field has field_behavior REQUIRED.
+func isRequired(field *desc.FieldDescriptor) bool {
+	eBehv, err := ext(field.GetFieldOptions(), annotations.E_FieldBehavior)
+
+	return err == nil && containBehavior(eBehv.([]annotations.FieldBehavior), annotations.FieldBehavior_REQUIRED)
+}
+
 func containStr(arr []string, str string) bool {
 	for _, s := range arr {
 		if s == str {
@@ -344,6 +328,17 @@ func containStr(arr []string, str string) bool {
 	return false
 }
 
+// indexStr returns the index of s in strs, or -1.
+func indexStr(strs []string, s string) int {
+	for i, str := range strs {
+		if str == s {
+			return i
+		}
+	}
+
+	return -1
+}
+
 func (v *validator) resolveServiceByName(name string) *desc.ServiceDescriptor {
 	for _, f := range v.files {
 		if s := f.FindService(name); s != nil {
@@ -386,6 +381,12 @@ func (v *validator) resolveRefFromCollections(name string) *config.CollectionCon
 
 func (v *validator) parseParameters(p string) error {
 	for _, s := range strings.Split(p, ",") {
+		switch s {
+		case "standard-methods":
+			v.standardMethods = true
+			continue
+		}
+
 		if e := strings.IndexByte(s, '='); e > 0 {
 			switch s[:e] {
 			case "gapic-yaml":
@@ -411,6 +412,38 @@ func (v *validator) parseParameters(p string) error {
 				if err != nil {
 					return fmt.Errorf("error decoding gapic config: %v", err)
 				}
+			case "service-yaml":
+				svc, err := loadServiceConfig(s[e+1:])
+				if err != nil {
+					return err
+				}
+
+				v.serviceConfig = svc
+			case "grpc-service-config":
+				gsc, err := loadGRPCServiceConfig(s[e+1:])
+				if err != nil {
+					return err
+				}
+
+				v.grpcServiceConfig = gsc
+			case "baseline":
+				files, err := loadBaseline(s[e+1:])
+				if err != nil {
+					return err
+				}
+
+				v.baseline = files
+			case "changed-files":
+				changed, err := loadChangedFiles(s[e+1:])
+				if err != nil {
+					return err
+				}
+
+				v.changed = changed
+			case "well-known-resources":
+				if err := v.loadWellKnownResources(s[e+1:]); err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -436,3 +469,23 @@ func snakeToCamel(s string) string {
 	}
 	return sb.String()
 }
+
+// lowerFirst lower cases the first rune of s, e.g. converting
+// UpperCamelCase to lowerCamelCase.
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
+
+// upperFirst upper cases the first rune of s, e.g. converting
+// lowerCamelCase to UpperCamelCase.
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
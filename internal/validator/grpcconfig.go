@@ -0,0 +1,168 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/code"
+)
+
+var durationRegexp = regexp.MustCompile(`^-?[0-9]+(\.[0-9]{1,9})?s$`)
+
+// grpcServiceConfig is the subset of the gRPC service config JSON, see
+// grpc/service_config/service_config.proto, that generators consume for
+// retries and timeouts.
+type grpcServiceConfig struct {
+	MethodConfig []struct {
+		Name []struct {
+			Service string `json:"service"`
+			Method  string `json:"method"`
+		} `json:"name"`
+		Timeout     string `json:"timeout"`
+		RetryPolicy *struct {
+			InitialBackoff       string        `json:"initialBackoff"`
+			MaxBackoff           string        `json:"maxBackoff"`
+			BackoffMultiplier    float64       `json:"backoffMultiplier"`
+			RetryableStatusCodes []interface{} `json:"retryableStatusCodes"`
+		} `json:"retryPolicy"`
+	} `json:"methodConfig"`
+}
+
+// loadGRPCServiceConfig reads and decodes the gRPC service config JSON at
+// path.
+func loadGRPCServiceConfig(path string) (*grpcServiceConfig, error) {
+	f, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading gRPC service config: %v", err)
+	}
+
+	gsc := &grpcServiceConfig{}
+	if err := json.Unmarshal(f, gsc); err != nil {
+		return nil, fmt.Errorf("error decoding gRPC service config: %v", err)
+	}
+
+	return gsc, nil
+}
+
+// validateGRPCServiceConfig checks that the gRPC service config method
+// configs refer to existing services and methods, each at most once, and
+// have well-formed timeouts and retry policies.
+func (v *validator) validateGRPCServiceConfig() {
+	// first methodConfig index matching each name
+	matched := map[string]int{}
+
+	for ndx, mc := range v.grpcServiceConfig.MethodConfig {
+		for _, name := range mc.Name {
+			key := name.Service
+			if name.Method != "" {
+				key += "/" + name.Method
+			}
+
+			if first, ok := matched[key]; ok {
+				v.addError(gscDuplicateName, first, ndx, key)
+			} else {
+				matched[key] = ndx
+			}
+
+			if name.Service == "" {
+				if name.Method != "" {
+					v.addError(gscMissingService, ndx, name.Method)
+				}
+				continue
+			}
+
+			serv := v.resolveServiceByName(name.Service)
+			if serv == nil {
+				serv = v.mixinService(name.Service)
+			}
+
+			if serv == nil {
+				v.addError(gscServiceDNE, ndx, name.Service)
+			} else if name.Method != "" && serv.FindMethodByName(name.Method) == nil {
+				v.addError(gscMethodDNE, ndx, name.Method, name.Service)
+			}
+		}
+
+		if mc.Timeout != "" {
+			if _, ok := parseDuration(mc.Timeout); !ok {
+				v.addError(gscInvalidDuration, ndx, "timeout", mc.Timeout)
+			}
+		}
+
+		rp := mc.RetryPolicy
+		if rp == nil {
+			continue
+		}
+
+		for _, b := range []struct {
+			field, value string
+		}{
+			{"initialBackoff", rp.InitialBackoff},
+			{"maxBackoff", rp.MaxBackoff},
+		} {
+			if d, ok := parseDuration(b.value); !ok {
+				v.addError(gscInvalidDuration, ndx, "retryPolicy."+b.field, b.value)
+			} else if d <= 0 {
+				v.addError(gscNonPositive, ndx, b.field, b.value)
+			}
+		}
+
+		if rp.BackoffMultiplier < 1 {
+			v.addError(gscMultiplier, ndx, rp.BackoffMultiplier)
+		}
+
+		if len(rp.RetryableStatusCodes) == 0 {
+			v.addError(gscNoStatusCodes, ndx)
+		}
+
+		for _, c := range rp.RetryableStatusCodes {
+			if !isRetryableCode(c) {
+				v.addError(gscInvalidStatusCode, ndx, c)
+			}
+		}
+	}
+}
+
+// isRetryableCode reports if c, either a google.rpc.Code name or number,
+// is a valid non-OK status code.
+func isRetryableCode(c interface{}) bool {
+	switch c := c.(type) {
+	case string:
+		val, ok := code.Code_value[c]
+		return ok && val != int32(code.Code_OK)
+	case float64:
+		_, ok := code.Code_name[int32(c)]
+		return ok && c == float64(int32(c)) && c != float64(code.Code_OK)
+	}
+
+	return false
+}
+
+// parseDuration parses a google.protobuf.Duration in its JSON form, e.g.
+// "0.100s".
+func parseDuration(s string) (time.Duration, bool) {
+	if !durationRegexp.MatchString(s) {
+		return 0, false
+	}
+
+	d, err := time.ParseDuration(s)
+
+	return d, err == nil
+}
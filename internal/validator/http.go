@@ -0,0 +1,206 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/genproto/googleapis/api/annotations"
+)
+
+var httpFieldPathRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// validateHTTPRule checks the path template, body and response_body of the
+// given HTTP rule, and its additional_bindings, against the method's request
+// and response messages. The rule is described by loc in any findings.
+func (v *validator) validateHTTPRule(rule *annotations.HttpRule, method *desc.MethodDescriptor, loc string) {
+	v.validateHTTPBinding(rule, method, loc)
+
+	for _, binding := range rule.GetAdditionalBindings() {
+		if len(binding.GetAdditionalBindings()) > 0 {
+			v.addError(httpNestedBindings, loc)
+		}
+
+		v.validateHTTPBinding(binding, method, loc)
+	}
+}
+
+// validateHTTPBinding checks a single HTTP binding, see validateHTTPRule.
+func (v *validator) validateHTTPBinding(rule *annotations.HttpRule, method *desc.MethodDescriptor, loc string) {
+	in, out := method.GetInputType(), method.GetOutputType()
+
+	verb, path := httpVerbAndPath(rule)
+	if path == "" {
+		v.addError(httpMissingPath, loc)
+		return
+	}
+
+	vars, err := parseHTTPPath(path)
+	if err != nil {
+		v.addError(httpInvalidPath, loc, path, err)
+	}
+
+	seen := map[string]bool{}
+	for _, fp := range vars {
+		if seen[fp] {
+			v.addError(httpDuplicateVar, loc, path, fp)
+			continue
+		}
+		seen[fp] = true
+
+		v.validateHTTPVariable(fp, in, loc)
+	}
+
+	switch body := rule.GetBody(); {
+	case body == "":
+	case verb == "get" || verb == "delete":
+		v.addError(httpBodyNotAllowed, loc, strings.ToUpper(verb))
+	case body != "*" && in.FindFieldByName(body) == nil:
+		v.addError(httpBodyDNE, loc, body, in.GetFullyQualifiedName())
+	}
+
+	if rb := rule.GetResponseBody(); rb != "" && out.FindFieldByName(rb) == nil {
+		v.addError(httpResponseBodyDNE, loc, rb, out.GetFullyQualifiedName())
+	}
+}
+
+// validateHTTPVariable ensures that the path variable's field path
+// resolves to a singular, primitive field of the request message.
+func (v *validator) validateHTTPVariable(fp string, in *desc.MessageDescriptor, loc string) {
+	msg := in
+	comps := strings.Split(fp, ".")
+
+	for ndx, comp := range comps {
+		f := msg.FindFieldByName(comp)
+		if f == nil || ndx < len(comps)-1 && f.GetMessageType() == nil {
+			v.addError(httpFieldDNE, loc, fp, in.GetFullyQualifiedName())
+			return
+		}
+
+		if f.IsRepeated() {
+			v.addError(httpFieldRepeated, loc, fp)
+			return
+		}
+
+		if ndx == len(comps)-1 && f.GetMessageType() != nil {
+			v.addError(httpFieldNotPrimitive, loc, fp, typeName(f))
+			return
+		}
+
+		msg = f.GetMessageType()
+	}
+}
+
+// httpVerbAndPath returns the lower case HTTP verb and path template of the
+// rule, if any.
+func httpVerbAndPath(rule *annotations.HttpRule) (string, string) {
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return "get", p.Get
+	case *annotations.HttpRule_Put:
+		return "put", p.Put
+	case *annotations.HttpRule_Post:
+		return "post", p.Post
+	case *annotations.HttpRule_Delete:
+		return "delete", p.Delete
+	case *annotations.HttpRule_Patch:
+		return "patch", p.Patch
+	case *annotations.HttpRule_Custom:
+		return strings.ToLower(p.Custom.GetKind()), p.Custom.GetPath()
+	}
+
+	return "", ""
+}
+
+// parseHTTPPath parses the given google.api.http path template, e.g.
+// "/v1/{name=shelves/*/books/*}:publish", and returns the field paths of its
+// variables, rejecting malformed templates with a *patternError.
+func parseHTTPPath(path string) ([]string, error) {
+	if path[0] != '/' {
+		return nil, &patternError{pos: 0, msg: "path must begin with '/'"}
+	}
+
+	var vars []string
+	segStart := 1
+
+	for i := 1; i < len(path); i++ {
+		switch path[i] {
+		case '{':
+			if i != segStart {
+				return nil, &patternError{pos: i, msg: "variable must be an entire segment"}
+			}
+
+			end := strings.IndexByte(path[i:], '}')
+			if end < 0 {
+				return nil, &patternError{pos: i, msg: "unbalanced '{'"}
+			}
+			end += i
+
+			inner := path[i+1 : end]
+			if n := strings.IndexByte(inner, '{'); n >= 0 {
+				return nil, &patternError{pos: i + 1 + n, msg: "nested '{' is not allowed"}
+			}
+
+			field := inner
+			if eq := strings.IndexByte(inner, '='); eq >= 0 {
+				field = inner[:eq]
+
+				for _, seg := range strings.Split(inner[eq+1:], "/") {
+					if seg == "" {
+						return nil, &patternError{pos: i + 2 + eq, msg: fmt.Sprintf("variable %q has an empty segment", inner)}
+					}
+				}
+			}
+
+			if !httpFieldPathRegexp.MatchString(field) {
+				return nil, &patternError{pos: i + 1, msg: fmt.Sprintf("invalid field path %q", field)}
+			}
+
+			vars = append(vars, field)
+			i = end
+
+			if i+1 < len(path) && path[i+1] != '/' && path[i+1] != ':' {
+				return nil, &patternError{pos: i + 1, msg: "variable must be an entire segment"}
+			}
+		case '}':
+			return nil, &patternError{pos: i, msg: "unbalanced '}'"}
+		case '/':
+			if i == segStart {
+				return nil, &patternError{pos: i, msg: "empty segment"}
+			}
+
+			segStart = i + 1
+		case ':':
+			if i == segStart {
+				return nil, &patternError{pos: i, msg: "empty segment"}
+			}
+
+			if verb := path[i+1:]; verb == "" || strings.ContainsAny(verb, "/{}:") {
+				return nil, &patternError{pos: i, msg: fmt.Sprintf("invalid custom verb %q", verb)}
+			}
+
+			return vars, nil
+		}
+	}
+
+	if segStart == len(path) {
+		return nil, &patternError{pos: len(path) - 1, msg: "trailing '/' is not allowed"}
+	}
+
+	return vars, nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"sort"
+
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/genproto/googleapis/api/annotations"
+
+	// register the mixin descriptors for desc.LoadFileDescriptor
+	_ "google.golang.org/genproto/googleapis/cloud/location"
+	_ "google.golang.org/genproto/googleapis/iam/v1"
+	_ "google.golang.org/genproto/googleapis/longrunning"
+)
+
+// mixinFiles maps the APIs that generators mix in to a service, when they
+// are listed in the service config apis, to the proto file defining them.
+var mixinFiles = map[string]string{
+	"google.cloud.location.Locations": "google/cloud/location/locations.proto",
+	"google.iam.v1.IAMPolicy":         "google/iam/v1/iam_policy.proto",
+	"google.longrunning.Operations":   "google/longrunning/operations.proto",
+}
+
+// noRESTMixinMethods are the mixin methods that have no standard REST
+// binding, and so are not expected to have a service config http rule.
+var noRESTMixinMethods = map[string]bool{
+	"google.longrunning.Operations.WaitOperation": true,
+}
+
+// mixinFile returns the descriptor of the file defining the named mixin
+// API, preferring the one given to the plugin, or nil if the API is not
+// a known mixin.
+func (v *validator) mixinFile(name string) *desc.FileDescriptor {
+	path, ok := mixinFiles[name]
+	if !ok {
+		return nil
+	}
+
+	if f, ok := v.files[path]; ok {
+		return f
+	}
+
+	f, err := desc.LoadFileDescriptor(path)
+	if err != nil {
+		return nil
+	}
+
+	return f
+}
+
+// mixinService returns the descriptor of the named mixin API, or nil if
+// the API is not a known mixin.
+func (v *validator) mixinService(name string) *desc.ServiceDescriptor {
+	if f := v.mixinFile(name); f != nil {
+		return f.FindService(name)
+	}
+
+	return nil
+}
+
+// isMixinMethod reports if the named method is provided by the given mixin
+// API, e.g. "GetIamPolicy" of "google.iam.v1.IAMPolicy".
+func (v *validator) isMixinMethod(api, method string) bool {
+	serv := v.mixinService(api)
+
+	return serv != nil && serv.FindMethodByName(method) != nil
+}
+
+// validateMixins ensures that the mixin APIs listed in the service config
+// apis are usable: the Operations mixin is listed if the services have
+// long-running methods, mixin http rules are only given for listed mixins
+// and, when the services are exposed over REST, each mixin method has an
+// http rule.
+func (v *validator) validateMixins(servs []*desc.ServiceDescriptor, mixins map[string]bool) {
+	svc := v.serviceConfig
+
+	rules := map[string]bool{}
+	for _, rule := range svc.GetHttp().GetRules() {
+		rules[rule.GetSelector()] = true
+
+		if m := v.resolveMethod(rule.GetSelector()); m != nil {
+			api := m.GetService().GetFullyQualifiedName()
+			if _, ok := mixinFiles[api]; ok && !mixins[api] {
+				v.addError(svcMixinNotListed, rule.GetSelector(), api)
+			}
+		}
+	}
+
+	var rest bool
+	for _, serv := range servs {
+		var lro bool
+		for _, m := range serv.GetMethods() {
+			if _, err := ext(m.GetMethodOptions(), annotations.E_Http); err == nil || rules[m.GetFullyQualifiedName()] {
+				rest = true
+			}
+
+			if m.GetOutputType().GetFullyQualifiedName() == "google.longrunning.Operation" {
+				lro = true
+			}
+		}
+
+		if lro && !mixins["google.longrunning.Operations"] {
+			v.addError(svcMissingOperationsMixin, serv.GetFullyQualifiedName())
+		}
+	}
+
+	if !rest {
+		return
+	}
+
+	var names []string
+	for name := range mixins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, m := range v.mixinService(name).GetMethods() {
+			if fqn := m.GetFullyQualifiedName(); !rules[fqn] && !noRESTMixinMethods[fqn] {
+				v.addError(svcMixinMissingHTTP, fqn)
+			}
+		}
+	}
+}
+
+// selectorFiles returns the files that service config selectors may refer
+// to: those given to the plugin, followed by any mixin files not among them.
+func (v *validator) selectorFiles() []*desc.FileDescriptor {
+	return v.index().selectors
+}
+
+// extraMixinFiles returns the known mixin files not in the file set.
+func (v *validator) extraMixinFiles() []*desc.FileDescriptor {
+	var files []*desc.FileDescriptor
+
+	var names []string
+	for name := range mixinFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, ok := v.files[mixinFiles[name]]; ok {
+			continue
+		}
+
+		if f := v.mixinFile(name); f != nil {
+			files = append(files, f)
+		}
+	}
+
+	return files
+}
+
+// resolveMethod finds the method with the given fully-qualified name among
+// the selectorFiles.
+func (v *validator) resolveMethod(fqn string) *desc.MethodDescriptor {
+	for _, f := range v.selectorFiles() {
+		if m, ok := f.FindSymbol(fqn).(*desc.MethodDescriptor); ok {
+			return m
+		}
+	}
+
+	return nil
+}
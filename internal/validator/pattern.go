@@ -0,0 +1,193 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var patternVarRegexp = regexp.MustCompile("^[a-z][a-z0-9]*(_[a-z0-9]+)*$")
+
+// resourcePattern is a parsed google.api.resource pattern, e.g.
+// "projects/{project}/books/{book}".
+type resourcePattern struct {
+	raw      string
+	segments []patternSegment
+}
+
+// patternSegment is a single slash-delimited component of a resource
+// pattern. A segment is either a collection identifier literal or one
+// or more template variables separated by literal characters.
+type patternSegment struct {
+	raw  string
+	vars []string
+}
+
+// isLiteral reports if the segment contains no template variables.
+func (s patternSegment) isLiteral() bool {
+	return len(s.vars) == 0
+}
+
+// patternError describes the first malformed portion of a resource
+// pattern and its zero-based character position.
+type patternError struct {
+	pos int
+	msg string
+}
+
+func (e *patternError) Error() string {
+	return fmt.Sprintf("position %d: %s", e.pos, e.msg)
+}
+
+// parsePattern parses the given resource pattern, rejecting malformed
+// templates with a *patternError.
+func parsePattern(pat string) (*resourcePattern, error) {
+	if pat == "" {
+		return nil, &patternError{pos: 0, msg: "pattern is empty"}
+	}
+	if pat[0] == '/' {
+		return nil, &patternError{pos: 0, msg: "leading '/' is not allowed"}
+	}
+	if pat[len(pat)-1] == '/' {
+		return nil, &patternError{pos: len(pat) - 1, msg: "trailing '/' is not allowed"}
+	}
+
+	p := &resourcePattern{raw: pat}
+	seen := map[string]bool{}
+
+	var seg patternSegment
+	segStart, varStart := 0, -1
+	// lastVarEnd is the position of the '}' closing the most recent
+	// variable in the current segment, or -1.
+	lastVarEnd := -1
+
+	for i := 0; i < len(pat); i++ {
+		switch c := pat[i]; {
+		case varStart >= 0 && c != '}':
+			if c == '{' {
+				return nil, &patternError{pos: i, msg: "nested '{' is not allowed"}
+			}
+		case c == '{':
+			if lastVarEnd >= 0 && lastVarEnd == i-1 {
+				return nil, &patternError{pos: i, msg: "adjacent variables must be separated by a literal"}
+			}
+			varStart = i
+		case c == '}':
+			if varStart < 0 {
+				return nil, &patternError{pos: i, msg: "unbalanced '}'"}
+			}
+
+			name := pat[varStart+1 : i]
+			switch {
+			case name == "":
+				return nil, &patternError{pos: varStart, msg: "variable name is empty"}
+			case strings.Contains(name, "="):
+				return nil, &patternError{pos: varStart, msg: fmt.Sprintf("variable captures like %q are not supported", pat[varStart:i+1])}
+			case !patternVarRegexp.MatchString(name):
+				return nil, &patternError{pos: varStart + 1, msg: fmt.Sprintf("variable %q must be lower snake_case", name)}
+			case seen[name]:
+				return nil, &patternError{pos: varStart + 1, msg: fmt.Sprintf("duplicate variable %q", name)}
+			}
+
+			seen[name] = true
+			seg.vars = append(seg.vars, name)
+			varStart, lastVarEnd = -1, i
+		case c == '/':
+			if i == segStart {
+				return nil, &patternError{pos: i, msg: "empty segment"}
+			}
+
+			seg.raw = pat[segStart:i]
+			p.segments = append(p.segments, seg)
+			seg = patternSegment{}
+			segStart, lastVarEnd = i+1, -1
+		}
+	}
+
+	if varStart >= 0 {
+		return nil, &patternError{pos: varStart, msg: "unbalanced '{'"}
+	}
+
+	seg.raw = pat[segStart:]
+	p.segments = append(p.segments, seg)
+
+	return p, nil
+}
+
+// lastVariable returns the final template variable name in the pattern,
+// or an empty string if the pattern has none.
+func (p *resourcePattern) lastVariable() string {
+	for i := len(p.segments) - 1; i >= 0; i-- {
+		if vars := p.segments[i].vars; len(vars) > 0 {
+			return vars[len(vars)-1]
+		}
+	}
+
+	return ""
+}
+
+// lastSegment returns the final segment of the pattern as written, e.g.
+// "settings" in "projects/{project}/settings".
+func (p *resourcePattern) lastSegment() string {
+	return p.segments[len(p.segments)-1].raw
+}
+
+// isSingleton reports if the pattern ends with a literal segment, e.g.
+// "projects/{project}/settings".
+func (p *resourcePattern) isSingleton() bool {
+	return p.segments[len(p.segments)-1].isLiteral()
+}
+
+// isSentinel reports if the pattern is a reserved sentinel value rather
+// than a resource name template, e.g. Pub/Sub's "_deleted-topic_".
+func (p *resourcePattern) isSentinel() bool {
+	return len(p.segments) == 1 && p.segments[0].isLiteral() &&
+		len(p.raw) > 2 && strings.HasPrefix(p.raw, "_") && strings.HasSuffix(p.raw, "_")
+}
+
+// collection returns the collection identifier preceding the final
+// template variable segment, e.g. "books" in "shelves/{shelf}/books/{book}".
+// It returns an empty string for singletons and patterns without one.
+func (p *resourcePattern) collection() string {
+	n := len(p.segments)
+	if n < 2 || p.isSingleton() || !p.segments[n-2].isLiteral() {
+		return ""
+	}
+
+	return p.segments[n-2].raw
+}
+
+// parent returns the pattern of the parent resource, e.g.
+// "shelves/{shelf}" for "shelves/{shelf}/books/{book}" or for the
+// singleton "shelves/{shelf}/settings". It returns an empty string for
+// top-level resources.
+func (p *resourcePattern) parent() string {
+	n := len(p.segments) - 2
+	if p.isSingleton() {
+		n = len(p.segments) - 1
+	}
+	if n <= 0 {
+		return ""
+	}
+
+	raw := make([]string, 0, n)
+	for _, seg := range p.segments[:n] {
+		raw = append(raw, seg.raw)
+	}
+
+	return strings.Join(raw, "/")
+}
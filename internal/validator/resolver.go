@@ -1,6 +1,9 @@
 package validator
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"google.golang.org/genproto/googleapis/api/annotations"
@@ -28,7 +31,7 @@ func (v *validator) resolveResRefMessage(typ string, file *desc.FileDescriptor)
 	// iterating over the entire file set of
 	// services is not ideal, but the unified
 	// resource design will go through some churn
-	for _, f := range v.files {
+	for _, f := range v.sortedFiles() {
 		if m := v.resolveResRefType(typ, f); m != nil {
 			return m
 		}
@@ -72,36 +75,324 @@ func (v *validator) resolveResRefType(typ string, f *desc.FileDescriptor) *desc.
 	return nil
 }
 
-// resolveMsgReference finds the MessageDescriptor for a fully qualified name
-// of an operation_info.response_type or operation_info.metadata_type.
-func (v *validator) resolveMsgReference(name string, file *desc.FileDescriptor) *desc.MessageDescriptor {
+// resolveWarning describes a reference that resolves, but only via a file
+// that is not imported. It is reported as a warning rather than an error.
+type resolveWarning struct {
+	error
+}
+
+// resolveMsgReference finds the MessageDescriptor for the given message
+// name, relative to the given file, following protobuf's scoping rules. An
+// error describes a reference that does not resolve as protoc would.
+func (v *validator) resolveMsgReference(name string, file *desc.FileDescriptor) (*desc.MessageDescriptor, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	targets := scopedNames(name, file.GetPackage())
+	visible := visibleFiles(file)
+	for _, target := range targets {
+		for _, f := range visible {
+			if msg := f.FindMessage(target); msg != nil {
+				return msg, nil
+			}
+		}
+	}
+
+	fqn := strings.TrimPrefix(name, ".")
+	qualified := strings.Contains(fqn, ".")
+
+	// the name resolves as protoc would, but the defining file isn't imported
+	for _, target := range targets {
+		if msg := v.findMessage(target); msg != nil {
+			return msg, resolveWarning{fmt.Errorf("resolves to %q in %q, which is not imported by %q",
+				msg.GetFullyQualifiedName(),
+				msg.GetFile().GetName(),
+				file.GetName())}
+		}
+	}
+
+	if qualified {
+		if strings.HasPrefix(fqn, "google.protobuf.") {
+			if msg, err := desc.LoadMessageDescriptor(fqn); err == nil && msg != nil {
+				return msg, resolveWarning{fmt.Errorf("resolves to well-known type %q, which is not imported by %q",
+					msg.GetFullyQualifiedName(),
+					file.GetName())}
+			}
+		}
+
+		// a qualified name defined nowhere is simply unresolvable
+		return nil, nil
+	}
+
+	// a bare message name defined in an unrelated package is not
+	// resolvable by protoc, but is almost certainly what was meant
+	var candidates []*desc.MessageDescriptor
+	for _, f := range v.sortedFiles() {
+		if msg := f.FindMessage(f.GetPackage() + "." + name); msg != nil {
+			candidates = append(candidates, msg)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, nil
+	case 1:
+		return candidates[0], fmt.Errorf("resolves to %q outside the scope of package %q, use the fully-qualified name",
+			candidates[0].GetFullyQualifiedName(),
+			file.GetPackage())
+	}
+
+	var names []string
+	for _, c := range candidates {
+		names = append(names, strconv.Quote(c.GetFullyQualifiedName()))
+	}
+
+	return nil, fmt.Errorf("is ambiguous, it could refer to any of %s", strings.Join(names, ", "))
+}
+
+// resolveServiceReference finds the ServiceDescriptor for the given service
+// name, relative to the given file, following the same scoping rules as
+// resolveMsgReference.
+func (v *validator) resolveServiceReference(name string, file *desc.FileDescriptor) *desc.ServiceDescriptor {
 	if name == "" {
 		return nil
 	}
 
-	// not a fully qualified name, make it one and check in parent file
-	//
-	// TODO(ndietz) this will break if the name refs a nested message
-	// in the parent file
-	if !strings.Contains(name, ".") {
-		if msg := file.FindMessage(file.GetPackage() + "." + name); msg != nil {
-			return msg
+	visible := visibleFiles(file)
+	for _, target := range scopedNames(name, file.GetPackage()) {
+		for _, f := range visible {
+			if serv := f.FindService(target); serv != nil {
+				return serv
+			}
 		}
 	}
 
-	// this Message must be imported or in a different file, check validator's
-	// file set. Iterating over the entire set isn't ideal, but necessary
-	// when searching for single message name in all protos
-	target := name
-	for _, f := range v.files {
-		if !strings.Contains(name, ".") {
-			target = f.GetPackage() + "." + name
+	return nil
+}
+
+// scopedNames returns the fully-qualified names that the given name may
+// refer to from within the given package, in the order protoc searches
+// them. A leading "." marks the name as already fully-qualified.
+func scopedNames(name, pkg string) []string {
+	if strings.HasPrefix(name, ".") {
+		return []string{name[1:]}
+	}
+
+	var targets []string
+	for _, scope := range scopes(pkg) {
+		if scope == "" {
+			targets = append(targets, name)
+		} else {
+			targets = append(targets, scope+"."+name)
 		}
+	}
+
+	return targets
+}
+
+// visibleFiles returns the files whose symbols protoc allows the given file
+// to reference: the file itself, its imports and their public imports.
+func visibleFiles(file *desc.FileDescriptor) []*desc.FileDescriptor {
+	visible := []*desc.FileDescriptor{file}
+	seen := map[string]bool{file.GetName(): true}
+
+	var public func(f *desc.FileDescriptor)
+	public = func(f *desc.FileDescriptor) {
+		for _, dep := range f.GetPublicDependencies() {
+			if !seen[dep.GetName()] {
+				seen[dep.GetName()] = true
+				visible = append(visible, dep)
+				public(dep)
+			}
+		}
+	}
+
+	for _, dep := range file.GetDependencies() {
+		if !seen[dep.GetName()] {
+			seen[dep.GetName()] = true
+			visible = append(visible, dep)
+			public(dep)
+		}
+	}
+
+	return visible
+}
+
+// scopes returns the enclosing scopes of the given package in which
+// protoc searches for relative names, innermost first, ending with the
+// root scope "".
+func scopes(pkg string) []string {
+	var s []string
+	for pkg != "" {
+		s = append(s, pkg)
+
+		if i := strings.LastIndex(pkg, "."); i >= 0 {
+			pkg = pkg[:i]
+		} else {
+			pkg = ""
+		}
+	}
+
+	return append(s, "")
+}
 
-		if msg := f.FindMessage(target); msg != nil {
+// findMessage finds the message, including nested messages, with the given
+// fully-qualified name in the file set.
+func (v *validator) findMessage(fqn string) *desc.MessageDescriptor {
+	for _, f := range v.sortedFiles() {
+		if msg := f.FindMessage(fqn); msg != nil {
 			return msg
 		}
 	}
 
 	return nil
 }
+
+// fileIndex holds the lookups over the file set that checks consult once
+// per element, so that they are built once per run rather than per call.
+type fileIndex struct {
+	files     []*desc.FileDescriptor
+	selectors []*desc.FileDescriptor
+	defs      []resourceDef
+	byType    map[string]resourceDef
+	byPackage map[string][]resourceDef
+	patterns  map[string]bool
+	inputs    map[string]bool
+}
+
+// index returns the fileIndex of the validator's file set, building it on
+// first use.
+func (v *validator) index() *fileIndex {
+	if v.idx != nil {
+		return v.idx
+	}
+
+	idx := &fileIndex{
+		byType:    map[string]resourceDef{},
+		byPackage: map[string][]resourceDef{},
+		patterns:  map[string]bool{},
+		inputs:    map[string]bool{},
+	}
+
+	names := make([]string, 0, len(v.files))
+	for name := range v.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := v.files[name]
+		idx.files = append(idx.files, f)
+
+		for _, serv := range f.GetServices() {
+			for _, method := range serv.GetMethods() {
+				idx.inputs[method.GetInputType().GetFullyQualifiedName()] = true
+			}
+		}
+	}
+	idx.selectors = append(idx.selectors, idx.files...)
+	idx.selectors = append(idx.selectors, v.extraMixinFiles()...)
+
+	idx.defs = collectResourceDefs(idx.files)
+	for _, d := range idx.defs {
+		if _, ok := idx.byType[d.res.GetType()]; !ok {
+			idx.byType[d.res.GetType()] = d
+		}
+
+		if d.msg != nil {
+			pkg := d.file.GetPackage()
+			idx.byPackage[pkg] = append(idx.byPackage[pkg], d)
+		}
+
+		for _, pat := range d.res.GetPattern() {
+			idx.patterns[pat] = true
+		}
+	}
+
+	for _, set := range []wellKnownSet{wellKnown, v.extraWellKnown} {
+		for pat := range set.patterns {
+			idx.patterns[pat] = true
+		}
+	}
+
+	v.idx = idx
+	return idx
+}
+
+// sortedFiles returns the validator's file set ordered by file name, so
+// that findings spanning multiple files are reported deterministically.
+func (v *validator) sortedFiles() []*desc.FileDescriptor {
+	return v.index().files
+}
+
+// resourceDef is a google.api.resource or google.api.resource_definition
+// declared somewhere in the file set.
+type resourceDef struct {
+	res  *annotations.ResourceDescriptor
+	file *desc.FileDescriptor
+	// msg is the annotated message, or nil for a resource_definition.
+	msg *desc.MessageDescriptor
+}
+
+// location describes where the resource is defined, including the line
+// number when source info is available.
+func (d resourceDef) location() string {
+	if d.msg == nil {
+		return fmt.Sprintf("resource_definition in %s", d.file.GetName())
+	}
+
+	loc := d.file.GetName()
+	if span := d.msg.GetSourceInfo().GetSpan(); len(span) > 0 {
+		loc = fmt.Sprintf("%s:%d", loc, span[0]+1)
+	}
+
+	return fmt.Sprintf("message %q in %s", d.msg.GetFullyQualifiedName(), loc)
+}
+
+// resourceDefs returns every resource defined in the file set, including
+// those on nested messages, ordered by file name.
+func (v *validator) resourceDefs() []resourceDef {
+	return v.index().defs
+}
+
+// collectResourceDefs collects every resource defined in the given files.
+func collectResourceDefs(files []*desc.FileDescriptor) []resourceDef {
+	var defs []resourceDef
+	for _, f := range files {
+		if eResDef, err := ext(f.GetFileOptions(), annotations.E_ResourceDefinition); err == nil {
+			for _, res := range eResDef.([]*annotations.ResourceDescriptor) {
+				defs = append(defs, resourceDef{res: res, file: f})
+			}
+		}
+
+		msgs := append([]*desc.MessageDescriptor(nil), f.GetMessageTypes()...)
+		for i := 0; i < len(msgs); i++ {
+			m := msgs[i]
+			msgs = append(msgs, m.GetNestedMessageTypes()...)
+
+			if eRes, err := ext(m.GetMessageOptions(), annotations.E_Resource); err == nil {
+				defs = append(defs, resourceDef{res: eRes.(*annotations.ResourceDescriptor), file: f, msg: m})
+			}
+		}
+	}
+
+	return defs
+}
+
+// resolveResourceDescriptor finds the first ResourceDescriptor in the file
+// set, either message-level or file-level, with the given type, falling
+// back to the well-known resources.
+func (v *validator) resolveResourceDescriptor(typ string) *annotations.ResourceDescriptor {
+	if d, ok := v.index().byType[typ]; ok {
+		return d.res
+	}
+
+	return v.wellKnownResource(typ)
+}
+
+// knownPatterns returns the set of every resource pattern defined in the
+// file set, plus the well-known patterns.
+func (v *validator) knownPatterns() map[string]bool {
+	return v.index().patterns
+}
@@ -0,0 +1,412 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/api/serviceconfig"
+	"google.golang.org/genproto/googleapis/cloud/extendedops"
+)
+
+// loadServiceConfig reads and decodes the google.api.Service YAML at path.
+func loadServiceConfig(path string) (*serviceconfig.Service, error) {
+	f, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading service config: %v", err)
+	}
+
+	j, err := yaml.YAMLToJSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding service config: %v", err)
+	}
+
+	// drop "type: google.api.Service" because that's not in the
+	// proto, causing an unmarshal error
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(j, &raw); err != nil {
+		return nil, fmt.Errorf("error decoding service config: %v", err)
+	}
+	delete(raw, "type")
+
+	j, err = json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding service config: %v", err)
+	}
+
+	svc := &serviceconfig.Service{}
+	if err := jsonpb.Unmarshal(bytes.NewReader(j), svc); err != nil {
+		return nil, fmt.Errorf("error decoding service config: %v", err)
+	}
+
+	return svc, nil
+}
+
+// validateServiceConfig checks the service config against the descriptors
+// it configures.
+func (v *validator) validateServiceConfig() {
+	svc := v.serviceConfig
+
+	if svc.GetName() == "" {
+		v.addError(svcMissingName)
+	}
+
+	// validate apis
+	var servs []*desc.ServiceDescriptor
+	mixins := map[string]bool{}
+	for _, api := range svc.GetApis() {
+		if _, ok := mixinFiles[api.GetName()]; ok {
+			if v.mixinService(api.GetName()) == nil {
+				v.addError(svcAPIDNE, api.GetName())
+			} else {
+				mixins[api.GetName()] = true
+			}
+			continue
+		}
+
+		serv := v.resolveServiceByName(api.GetName())
+		if serv == nil {
+			v.addError(svcAPIDNE, api.GetName())
+			continue
+		}
+		servs = append(servs, serv)
+
+		if svc.GetName() == "" {
+			continue
+		}
+
+		eHost, err := ext(serv.GetServiceOptions(), annotations.E_DefaultHost)
+		if err != nil {
+			continue
+		}
+
+		// default_host may include a port, e.g. "library.googleapis.com:443"
+		host := *eHost.(*string)
+		if h := strings.Split(host, ":")[0]; h != "" && h != svc.GetName() {
+			v.addError(svcNameMismatch, svc.GetName(), host, serv.GetFullyQualifiedName())
+		}
+	}
+
+	// validate http rules, which override or add bindings for methods,
+	// including those of mixins
+	for _, rule := range svc.GetHttp().GetRules() {
+		if method := v.resolveMethod(rule.GetSelector()); method != nil {
+			v.validateHTTPRule(rule, method, fmt.Sprintf("service config http.rules selector %q", rule.GetSelector()))
+		} else if !v.selectorResolves(rule.GetSelector(), isMethod) {
+			v.addError(svcHTTPSelectorDNE, rule.GetSelector())
+		}
+	}
+
+	// validate documentation rules
+	for _, rule := range svc.GetDocumentation().GetRules() {
+		if !v.selectorResolves(rule.GetSelector(), isAny) {
+			v.addError(svcDocSelectorDNE, rule.GetSelector())
+		}
+	}
+
+	v.validateMixins(servs, mixins)
+
+	if pub := svc.GetPublishing(); pub != nil {
+		v.validatePublishing(pub)
+		v.validateSelectiveGeneration(pub, servs)
+	}
+}
+
+// validatePublishing checks that the names referenced by the publishing
+// method_settings and library_settings resolve, and that the settings are
+// applicable to what they refer to.
+func (v *validator) validatePublishing(pub *annotations.Publishing) {
+	for _, ms := range pub.GetMethodSettings() {
+		sel := ms.GetSelector()
+
+		method := v.resolveMethod(sel)
+		if method == nil {
+			v.addError(svcPubMethodDNE, sel)
+			continue
+		}
+
+		if ms.GetLongRunning() != nil && method.GetOutputType().GetFullyQualifiedName() != "google.longrunning.Operation" {
+			v.addError(svcPubLongRunningNotLRO, sel)
+		}
+
+		in := method.GetInputType()
+		for _, name := range ms.GetAutoPopulatedFields() {
+			field := in.FindFieldByName(name)
+			if field == nil {
+				v.addError(svcPubAutoPopDNE, sel, name, in.GetFullyQualifiedName())
+				continue
+			}
+
+			if field.GetType() != descriptor.FieldDescriptorProto_TYPE_STRING || field.IsRepeated() {
+				v.addError(svcPubAutoPopNotString, sel, name)
+			}
+
+			// the field_behavior of UUID4 fields is checked with their
+			// google.api.field_info
+			eInfo, err := ext(field.GetFieldOptions(), annotations.E_FieldInfo)
+			if err != nil || eInfo.(*annotations.FieldInfo).GetFormat() != annotations.FieldInfo_UUID4 {
+				v.addError(svcPubAutoPopNotUUID4, sel, name)
+			}
+		}
+	}
+
+	for _, ls := range pub.GetLibrarySettings() {
+		if ver := ls.GetVersion(); ver != "" && !v.hasPackage(ver) {
+			v.addError(svcPubVersionDNE, ver)
+		}
+
+		for _, name := range sortedKeys(ls.GetJavaSettings().GetServiceClassNames()) {
+			if v.resolveServiceByName(name) == nil {
+				v.addError(svcPubServiceDNE, "java_settings.service_class_names", name)
+			}
+		}
+
+		for _, name := range sortedKeys(ls.GetDotnetSettings().GetRenamedServices()) {
+			if !v.hasServiceNamed(name) {
+				v.addError(svcPubServiceDNE, "dotnet_settings.renamed_services", name)
+			}
+		}
+
+		for _, name := range sortedKeys(ls.GetGoSettings().GetRenamedServices()) {
+			if !v.hasServiceNamed(name) {
+				v.addError(svcPubServiceDNE, "go_settings.renamed_services", name)
+			}
+		}
+
+		for _, typ := range sortedKeys(ls.GetDotnetSettings().GetRenamedResources()) {
+			if v.resolveResourceDescriptor(typ) == nil {
+				v.addError(svcPubResourceDNE, "dotnet_settings.renamed_resources", typ)
+			}
+		}
+
+		for _, typ := range ls.GetDotnetSettings().GetIgnoredResources() {
+			if v.resolveResourceDescriptor(typ) == nil {
+				v.addError(svcPubResourceDNE, "dotnet_settings.ignored_resources", typ)
+			}
+		}
+	}
+}
+
+// validateSelectiveGeneration checks each language's selective GAPIC
+// generation methods: that they exist, that the methods they depend on are
+// also selected and which of the given services would be left empty.
+func (v *validator) validateSelectiveGeneration(pub *annotations.Publishing, servs []*desc.ServiceDescriptor) {
+	for _, ls := range pub.GetLibrarySettings() {
+		for _, lang := range languageSettings(ls) {
+			methods := lang.common.GetSelectiveGapicGeneration().GetMethods()
+			if len(methods) == 0 {
+				continue
+			}
+
+			selected := map[string]bool{}
+			for _, name := range methods {
+				selected[name] = true
+
+				if v.resolveMethod(name) == nil {
+					v.addError(svcSelectiveMethodDNE, lang.name, name)
+				}
+			}
+
+			for _, name := range methods {
+				m := v.resolveMethod(name)
+				if m == nil {
+					continue
+				}
+
+				if m.GetOutputType().GetFullyQualifiedName() == "google.longrunning.Operation" {
+					if dep := "google.longrunning.Operations.GetOperation"; !selected[dep] {
+						v.addError(svcSelectiveDangling, lang.name, name, dep)
+					}
+				}
+
+				if eServ, err := ext(m.GetMethodOptions(), extendedops.E_OperationService); err == nil {
+					serv := v.resolveServiceReference(*eServ.(*string), m.GetFile())
+					if serv == nil {
+						continue
+					}
+
+					if polling := pollingMethods(serv); len(polling) == 1 && !selected[polling[0].GetFullyQualifiedName()] {
+						v.addError(svcSelectiveDangling, lang.name, name, polling[0].GetFullyQualifiedName())
+					}
+				}
+			}
+
+			for _, serv := range servs {
+				var used bool
+				for _, m := range serv.GetMethods() {
+					used = used || selected[m.GetFullyQualifiedName()]
+				}
+
+				if !used {
+					v.addWarning(svcSelectiveEmpty, lang.name, serv.GetFullyQualifiedName())
+				}
+			}
+		}
+	}
+}
+
+// languageSetting is the common settings of a single language's library
+// settings, e.g. "java_settings".
+type languageSetting struct {
+	name   string
+	common *annotations.CommonLanguageSettings
+}
+
+// languageSettings returns the common settings of each language configured
+// in the library settings.
+func languageSettings(ls *annotations.ClientLibrarySettings) []languageSetting {
+	var langs []languageSetting
+	for _, l := range []languageSetting{
+		{"java_settings", ls.GetJavaSettings().GetCommon()},
+		{"cpp_settings", ls.GetCppSettings().GetCommon()},
+		{"php_settings", ls.GetPhpSettings().GetCommon()},
+		{"python_settings", ls.GetPythonSettings().GetCommon()},
+		{"node_settings", ls.GetNodeSettings().GetCommon()},
+		{"dotnet_settings", ls.GetDotnetSettings().GetCommon()},
+		{"ruby_settings", ls.GetRubySettings().GetCommon()},
+		{"go_settings", ls.GetGoSettings().GetCommon()},
+	} {
+		if l.common != nil {
+			langs = append(langs, l)
+		}
+	}
+
+	return langs
+}
+
+// sortedKeys returns the keys of the map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// hasPackage reports if any file defines the given proto package.
+func (v *validator) hasPackage(pkg string) bool {
+	for _, f := range v.files {
+		if f.GetPackage() == pkg {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasServiceNamed reports if any file defines a service with the given
+// unqualified name.
+func (v *validator) hasServiceNamed(name string) bool {
+	for _, f := range v.files {
+		for _, serv := range f.GetServices() {
+			if serv.GetName() == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// selectorResolves reports if the service config selector refers to at
+// least one element accepted by match. A selector is the fully-qualified
+// name of an element, optionally ending in a "*" wildcard, e.g.
+// "google.example.library.v1.LibraryService.*".
+func (v *validator) selectorResolves(sel string, match func(desc.Descriptor) bool) bool {
+	if sel == "" {
+		return false
+	}
+	prefix := strings.TrimSuffix(sel, "*")
+	wildcard := prefix != sel
+
+	for _, f := range v.selectorFiles() {
+		for _, d := range descriptors(f) {
+			if !match(d) {
+				continue
+			}
+
+			name := d.GetFullyQualifiedName()
+			if name == sel || wildcard && strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// descriptors returns every service, method, message, field, enum and enum
+// value defined in the file, including nested ones.
+func descriptors(f *desc.FileDescriptor) []desc.Descriptor {
+	var ds []desc.Descriptor
+
+	var enum func(e *desc.EnumDescriptor)
+	enum = func(e *desc.EnumDescriptor) {
+		ds = append(ds, e)
+		for _, val := range e.GetValues() {
+			ds = append(ds, val)
+		}
+	}
+
+	var msg func(m *desc.MessageDescriptor)
+	msg = func(m *desc.MessageDescriptor) {
+		ds = append(ds, m)
+		for _, field := range m.GetFields() {
+			ds = append(ds, field)
+		}
+		for _, n := range m.GetNestedMessageTypes() {
+			msg(n)
+		}
+		for _, e := range m.GetNestedEnumTypes() {
+			enum(e)
+		}
+	}
+
+	for _, serv := range f.GetServices() {
+		ds = append(ds, serv)
+		for _, mthd := range serv.GetMethods() {
+			ds = append(ds, mthd)
+		}
+	}
+	for _, m := range f.GetMessageTypes() {
+		msg(m)
+	}
+	for _, e := range f.GetEnumTypes() {
+		enum(e)
+	}
+
+	return ds
+}
+
+func isMethod(d desc.Descriptor) bool {
+	_, ok := d.(*desc.MethodDescriptor)
+	return ok
+}
+
+func isAny(desc.Descriptor) bool {
+	return true
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/genproto/googleapis/api/annotations"
+)
+
+// standardVerbs are the AIP standard method name prefixes.
+var standardVerbs = []string{"Get", "List", "Create", "Update", "Delete"}
+
+// validateStandardMethod checks the GAPIC-relevant shape of an AIP standard
+// method, recognised by its name prefix and the resource it operates on:
+//
+//   - Get and Delete take a name with a resource_reference to the resource
+//   - List and Create take a parent with a resource_reference child_type of
+//     the resource, unless the resource is top-level
+//   - Update takes the resource and an update_mask, and has a
+//     method_signature of "resource,update_mask"
+func (v *validator) validateStandardMethod(method *desc.MethodDescriptor) {
+	verb, res, msg := v.standardResource(method)
+	if res == nil {
+		return
+	}
+
+	mFQN := method.GetFullyQualifiedName()
+	in := method.GetInputType()
+
+	switch verb {
+	case "Get", "Delete":
+		v.validateStandardRef(method, "name", "type", res.GetType())
+	case "List", "Create":
+		if pats := res.GetPattern(); len(pats) > 0 {
+			if p, err := parsePattern(pats[0]); err == nil && p.parent() == "" {
+				// top-level resources have no parent
+				return
+			}
+		}
+
+		v.validateStandardRef(method, "parent", "child_type", res.GetType())
+	case "Update":
+		if msg == nil {
+			return
+		}
+
+		field := camelToSnake(msg.GetName())
+		if f := in.FindFieldByName(field); f == nil {
+			v.addError(stdMissingField, mFQN, in.GetFullyQualifiedName(), field)
+		} else if mt := f.GetMessageType(); mt == nil || mt.GetFullyQualifiedName() != msg.GetFullyQualifiedName() {
+			v.addError(stdFieldType, mFQN, f.GetFullyQualifiedName(), msg.GetFullyQualifiedName(), typeName(f))
+		}
+
+		if f := in.FindFieldByName("update_mask"); f == nil {
+			v.addError(stdMissingField, mFQN, in.GetFullyQualifiedName(), "update_mask")
+		} else if mt := f.GetMessageType(); mt == nil || mt.GetFullyQualifiedName() != "google.protobuf.FieldMask" {
+			v.addError(stdFieldType, mFQN, f.GetFullyQualifiedName(), "google.protobuf.FieldMask", typeName(f))
+		}
+
+		want := field + ",update_mask"
+		var sigs []string
+		if eSig, err := ext(method.GetMethodOptions(), annotations.E_MethodSignature); err == nil {
+			sigs = eSig.([]string)
+		}
+
+		var found bool
+		for _, sig := range sigs {
+			found = found || strings.ReplaceAll(sig, " ", "") == want
+		}
+
+		if !found {
+			v.addError(stdMissingSignature, mFQN, want)
+		}
+	}
+}
+
+// validateStandardRef ensures that the standard method's request has the
+// named string field with a resource_reference of the given kind, "type" or
+// "child_type", to the resource type.
+func (v *validator) validateStandardRef(method *desc.MethodDescriptor, field, kind, typ string) {
+	mFQN := method.GetFullyQualifiedName()
+	in := method.GetInputType()
+
+	f := in.FindFieldByName(field)
+	if f == nil {
+		v.addError(stdMissingField, mFQN, in.GetFullyQualifiedName(), field)
+		return
+	}
+
+	var ref *annotations.ResourceReference
+	if eRef, err := ext(f.GetFieldOptions(), annotations.E_ResourceReference); err == nil {
+		ref = eRef.(*annotations.ResourceReference)
+	}
+
+	got := ref.GetType()
+	if kind == "child_type" {
+		got = ref.GetChildType()
+	}
+
+	if got != typ {
+		v.addError(stdMissingRef, mFQN, f.GetFullyQualifiedName(), kind, typ)
+	}
+}
+
+// standardResource returns the standard method verb and the resource the
+// method operates on, identified by the rest of the method name: the
+// resource message name, or its plural for List. The resource must be
+// defined in the method's package.
+func (v *validator) standardResource(method *desc.MethodDescriptor) (string, *annotations.ResourceDescriptor, *desc.MessageDescriptor) {
+	name := method.GetName()
+	pkg := method.GetFile().GetPackage()
+
+	for _, verb := range standardVerbs {
+		noun := strings.TrimPrefix(name, verb)
+		if noun == name || noun == "" {
+			continue
+		}
+
+		for _, d := range v.index().byPackage[pkg] {
+			if verb != "List" && d.msg.GetName() == noun {
+				return verb, d.res, d.msg
+			}
+
+			plural := d.res.GetPlural()
+			if plural == "" {
+				plural = d.msg.GetName() + "s"
+			}
+
+			if verb == "List" && strings.EqualFold(plural, noun) {
+				return verb, d.res, d.msg
+			}
+		}
+	}
+
+	return "", nil, nil
+}
+
+// camelToSnake converts an UpperCamelCase message name to the
+// lower_snake_case name of a field of that type, e.g. "BookShelf" to
+// "book_shelf".
+func camelToSnake(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
@@ -16,15 +16,20 @@ package validator
 
 import (
 	"fmt"
+	"os"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/googleapis/gapic-config-validator/internal/config"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
 	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
 	"github.com/jhump/protoreflect/desc"
 	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/api/serviceconfig"
+	"google.golang.org/genproto/googleapis/cloud/extendedops"
 	"google.golang.org/genproto/googleapis/longrunning"
 )
 
@@ -39,34 +44,169 @@ const (
 	missingLROMetadataType      = "rpc %q has google.longrunning.operation_info but is missing option google.longrunning.operation_info.metadata_type"
 	unresolvableLROResponseType = "unable to resolve google.longrunning.operation_info.response_type value %q in rpc %q"
 	unresolvableLROMetadataType = "unable to resolve google.longrunning.operation_info.metadata_type value %q in rpc %q"
+	lroResponseIsOperation      = "rpc %q google.longrunning.operation_info.response_type must not be google.longrunning.Operation"
+	lroEmptyNotQualified        = "rpc %q google.longrunning.operation_info.%s %q must be written as google.protobuf.Empty"
+	lroTypeResolution           = "google.longrunning.operation_info.%s value %q in rpc %q %v"
+	lroMetadataIsResponse       = "rpc %q google.longrunning.operation_info.metadata_type must not be the same as response_type %q"
+
+	// extended LRO related errors
+	extOpServiceWithLRO     = "rpc %q returns google.longrunning.Operation and must not have option google.cloud.operation_service"
+	extOpServiceDNE         = "rpc %q google.cloud.operation_service %q does not resolve to a service"
+	extOpNotCustomOperation = "rpc %q has google.cloud.operation_service but response %q has no fields annotated google.cloud.operation_field"
+	extOpNoPollingMethod    = "service %q referenced by rpc %q google.cloud.operation_service has no method annotated google.cloud.operation_polling_method"
+	extOpMultiplePolling    = "service %q must have exactly one method annotated google.cloud.operation_polling_method, found %s"
+	extOpPollingResponse    = "polling rpc %q must return %q, the operation returned by rpc %q, not %q"
+	extOpPollingNotCustom   = "polling rpc %q response %q has no fields annotated google.cloud.operation_field"
+	extOpPollingNoMapping   = "polling rpc %q request %q has no fields annotated google.cloud.operation_response_field"
+	extOpRequestFieldDNE    = "field %q google.cloud.operation_request_field %q does not exist in polling request %q"
+	extOpResponseFieldDNE   = "field %q google.cloud.operation_response_field %q does not exist in operation %q"
+	extOpFieldUndefined     = "field %q google.cloud.operation_field must not be UNDEFINED"
+	extOpFieldMissing       = "custom operation %q is missing a field annotated google.cloud.operation_field %s"
+	extOpFieldDuplicate     = "custom operation %q has multiple fields annotated google.cloud.operation_field %s: %s"
+	extOpFieldType          = "custom operation field %q annotated google.cloud.operation_field %s must be %s, not %s"
+
+	// streaming related errors
+	streamMethodSignature = "%s rpc %q must not have option google.api.method_signature"
+	streamOperationInfo   = "%s rpc %q must not have option google.longrunning.operation_info"
+	streamHTTP            = "%s rpc %q must not have option google.api.http"
+	streamPaginated       = "server-streaming rpc %q must not have pagination fields, generators do not page streamed responses"
+
+	// pagination related errors
+	pageFieldType       = "rpc %q looks paginated but field %q must be %s, not %s"
+	pageMissingField    = "rpc %q looks paginated but %q is missing field %q"
+	pageNoResults       = "rpc %q looks paginated but response %q has no repeated field to page over"
+	pageMultipleResults = "rpc %q looks paginated but response %q has multiple repeated fields: %s"
+	pageMapResults      = "rpc %q looks paginated but response %q field %q is a map, which cannot be paged over"
+
+	// google.api.http related errors
+	httpMissingPath       = "%s is missing a path"
+	httpInvalidPath       = "%s path template %q is malformed at %v"
+	httpDuplicateVar      = "%s path template %q binds field %q more than once"
+	httpFieldDNE          = "%s path variable %q does not resolve to a field in %q"
+	httpFieldRepeated     = "%s path variable %q must not refer to a repeated field"
+	httpFieldNotPrimitive = "%s path variable %q must refer to a primitive field, not %s"
+	httpBodyNotAllowed    = "%s must not have a body for %s"
+	httpBodyDNE           = "%s body %q is not a field in %q"
+	httpResponseBodyDNE   = "%s response_body %q is not a field in %q"
+	httpNestedBindings    = "%s additional_bindings must not have additional_bindings"
+
+	// service config related errors
+	svcMissingName     = "service config is missing name"
+	svcAPIDNE          = "service config apis entry %q does not exist"
+	svcNameMismatch    = "service config name %q does not match google.api.default_host %q of service %q"
+	svcHTTPSelectorDNE = "service config http.rules selector %q does not resolve to a method"
+	svcDocSelectorDNE  = "service config documentation.rules selector %q does not resolve to any element"
+
+	// mixin related errors
+	svcMixinNotListed         = "service config http.rules selector %q configures mixin %q, which is not listed in apis"
+	svcMixinMissingHTTP       = "mixin method %q is missing a service config http.rules entry, it will not be available over REST"
+	svcMissingOperationsMixin = "service %q has long-running methods but google.longrunning.Operations is not listed in service config apis"
+
+	// publishing settings related errors
+	svcPubMethodDNE         = "service config publishing.method_settings selector %q does not resolve to a method"
+	svcPubLongRunningNotLRO = "service config publishing.method_settings selector %q has long_running settings but does not return google.longrunning.Operation"
+	svcPubAutoPopDNE        = "service config publishing.method_settings selector %q auto_populated_fields %q is not a field in %q"
+	svcPubAutoPopNotString  = "service config publishing.method_settings selector %q auto_populated_fields %q must be a singular string field"
+	svcPubAutoPopNotUUID4   = "service config publishing.method_settings selector %q auto_populated_fields %q must have google.api.field_info.format UUID4"
+	svcPubVersionDNE        = "service config publishing.library_settings version %q does not match any proto package"
+	svcPubServiceDNE        = "service config publishing.library_settings %s %q does not resolve to a service"
+	svcPubResourceDNE       = "service config publishing.library_settings %s %q does not resolve to a resource"
+
+	// selective GAPIC generation related errors
+	svcSelectiveMethodDNE = "service config publishing.library_settings %s selective_gapic_generation method %q does not resolve to a method"
+	svcSelectiveDangling  = "service config publishing.library_settings %s selective_gapic_generation includes %q but not %q, which it depends on"
+	svcSelectiveEmpty     = "service config publishing.library_settings %s selective_gapic_generation leaves service %q without any methods"
+
+	// standard method related errors
+	stdMissingField     = "standard method %q request %q is missing field %q"
+	stdFieldType        = "standard method %q field %q must be %s, not %s"
+	stdMissingRef       = "standard method %q field %q must have a google.api.resource_reference %s of %q"
+	stdMissingSignature = "standard method %q must have google.api.method_signature %q"
+
+	// gRPC service config related errors
+	gscMissingService    = "gRPC service config methodConfig[%d] name has method %q but no service"
+	gscServiceDNE        = "gRPC service config methodConfig[%d] name service %q does not exist"
+	gscMethodDNE         = "gRPC service config methodConfig[%d] name method %q does not exist in service %q"
+	gscDuplicateName     = "gRPC service config methodConfig[%d] and methodConfig[%d] both match %q"
+	gscInvalidDuration   = "gRPC service config methodConfig[%d] %s %q is not a valid duration"
+	gscNonPositive       = "gRPC service config methodConfig[%d] retryPolicy.%s must be positive, not %q"
+	gscMultiplier        = "gRPC service config methodConfig[%d] retryPolicy.backoffMultiplier must be at least 1, not %v"
+	gscNoStatusCodes     = "gRPC service config methodConfig[%d] retryPolicy.retryableStatusCodes must not be empty"
+	gscInvalidStatusCode = "gRPC service config methodConfig[%d] retryPolicy.retryableStatusCodes has invalid status code %v"
+
+	// breaking change related errors
+	brkDefaultHost  = "service %q google.api.default_host changed from %q to %q"
+	brkSigRemoved   = "rpc %q removed google.api.method_signature %q"
+	brkSigReordered = "rpc %q moved google.api.method_signature %q before %q"
+	brkLROType      = "rpc %q operation_info.%s changed from %q to %q"
+	brkResType      = "resource message %q google.api.resource.type changed from %q to %q"
+	brkResPattern   = "resource %q removed pattern %q"
+	brkNameField    = "resource %q name_field changed from %q to %q"
+	brkRequired     = "field %q field_behavior changed to REQUIRED"
 
 	// method_signature related errors
 	fieldDNE               = "field %q listed in rpc %q method signature entry (%q) does not exist in %q"
 	requiredAfterOptional  = "rpc %q method signature entry (%q) lists required field %q after an optional field"
 	fieldComponentRepeated = "rpc %q method signature entry field %q cannot be a field within a repeated field"
+	sigSuggestion          = "rpc %q has no google.api.method_signature, consider adding %q to generate a flattened overload"
 
 	// resource reslated errors
 	resRefNotValidResource  = "unable to resolve resource reference for field %q: value %q is not a valid resource"
 	resRefFieldDNE          = "unable to resolve resource reference for field %q: field does not exist or is not defined on message %q"
+	resRefChildNoParent     = "child_type %q on field %q refers to a top-level resource with no parent"
+	resRefChildNoKnown      = "child_type %q on field %q has no known parent resource, expected one of %s"
+	resRefNotString         = "resource_reference on field %q must be on a string field, not %s"
+	resRefOnNameField       = "resource_reference on field %q is redundant, it is the name field of resource message %q"
 	resRefInvalidTypeFormat = "resource_reference.(child_)type for field %q must be {service_name}/{resource_type_kind}"
 	resMissingType          = "resource for message %q missing field google.api.resource.type"
 	resInvalidTypeFormat    = "resource.(child_)type for message %q must be {service_name}/{resource_type_kind}"
 	resTypeKindInvalid      = "resource_type_kind %q has invalid format, must match regexp [A-Z][a-zA-Z0-9]+"
 	resTypeKindTooLong      = "resource_type_kind in message %q must not be longer than %d characters"
 	resMissingPattern       = "field %q resource missing pattern definition"
+	resInvalidPattern       = "resource %q pattern %q is malformed at %v"
+	resPatternVarMismatch   = "resource %q patterns must end with the same variable: %q ends with {%s} but %q ends with {%s}"
+	resSingletonMismatch    = "resource %q patterns must all be singletons or all not: %q is a singleton but %q is not"
+	resSingletonLiteral     = "resource %q singleton patterns must end with the same segment: %q ends with %q but %q ends with %q"
+	resPluralMismatch       = "resource %q plural %q does not match collection %q in pattern %q"
+	resSingularMismatch     = "resource %q singular %q does not match final segment %q in pattern %q"
+	resSingularNotTypeKind  = "resource %q singular %q must be the lowerCamelCase resource_type_kind %q"
+	resPluralInvalid        = "resource %q plural %q must be lowerCamelCase"
+	resPluralIsSingular     = "resource %q plural %q must be different from singular"
+	resFutureMultiPattern   = "resource %q history FUTURE_MULTI_PATTERN must have exactly one pattern, found %d"
+	resOrigSinglePattern    = "resource %q history ORIGINALLY_SINGLE_PATTERN must have multiple patterns, found %d"
+	resDeclMissingEtag      = "declarative-friendly resource message %q missing a string etag field"
+	resTypeConflict         = "resource type %q has conflicting definitions: %s"
+	resTypeRedefined        = "resource type %q is defined by a message and by an identical resource_definition, only one is used: %s"
+	resPatternConflict      = "pattern %q is claimed by multiple resource types: %s"
+	resDeclMissingValidate  = "rpc %q mutates declarative-friendly resource %q but %q is missing a bool validate_only field"
 	resMissingNameField     = "resource message %q missing a name field"
+	resNameFieldNotString   = "resource name field %q must be a string field"
+	resNameFieldRepeated    = "resource name field %q must not be repeated"
+	resNameFieldInOneof     = "resource name field %q must not be part of oneof %q"
+	resNameFieldConflict    = "resource name field %q must not be annotated %s"
+	resNameFieldNotIdent    = "resource name field %q has google.api.field_behavior but is not annotated IDENTIFIER"
+	resNameFieldIdentOutput = "resource name field %q must not be annotated both IDENTIFIER and OUTPUT_ONLY"
+
+	// field_info related errors
+	fieldInfoNotString        = "field %q has google.api.field_info.format %s but is not a string field"
+	fieldInfoRepeated         = "field %q has google.api.field_info.format %s, which is not supported on repeated fields"
+	fieldInfoUUID4Required    = "request field %q has google.api.field_info.format UUID4, it must not be REQUIRED so that it can be auto-populated"
+	fieldInfoUUID4NotOptional = "request field %q has google.api.field_info.format UUID4, it must be annotated OPTIONAL so that it can be auto-populated"
 
 	maxCharRescTypeKind = 100
 )
 
 var (
 	resourceTypeKindRegexp *regexp.Regexp
-	wellKnownTypes         = map[string]bool{
-		"cloudresourcemanager.googleapis.com/Project":      true,
-		"cloudresourcemanager.googleapis.com/Organization": true,
-		"cloudresourcemanager.googleapis.com/Folder":       true,
-		"locations.googleapis.com/Location":                true,
-		"cloudbilling.googleapis.com/BillingAccount":       true,
+	lowerCamelRegexp       = regexp.MustCompile("^[a-z][a-zA-Z0-9]*$")
+
+	// repeatedFormats are the field_info formats that may be applied to
+	// each element of a repeated string field. UUID4 is excluded because
+	// generators only auto-populate singular fields.
+	repeatedFormats = map[annotations.FieldInfo_Format]bool{
+		annotations.FieldInfo_IPV4:         true,
+		annotations.FieldInfo_IPV6:         true,
+		annotations.FieldInfo_IPV4_OR_IPV6: true,
 	}
 )
 
@@ -92,22 +232,60 @@ func Validate(req *plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse,
 		v.compare()
 	}
 
-	for _, name := range req.GetFileToGenerate() {
+	if v.serviceConfig != nil {
+		v.validateServiceConfig()
+	}
+
+	if v.grpcServiceConfig != nil {
+		v.validateGRPCServiceConfig()
+	}
+
+	toGen := req.GetFileToGenerate()
+	if v.changed != nil {
+		toGen = filesToValidate(req.GetProtoFile(), toGen, v.changed)
+	}
+
+	gen := map[string]bool{}
+	for _, name := range toGen {
 		rich, ok := v.files[name]
 		if !ok {
 			return &v.resp, fmt.Errorf("FileToGenerate (%s) did not have a rich descriptor", name)
 		}
+		gen[name] = true
 
 		v.validate(rich)
+
+		if v.baseline != nil {
+			v.validateBreaking(rich)
+		}
+	}
+
+	v.validateResourceUniqueness(gen)
+
+	// warnings do not fail validation, report them on stderr
+	for _, w := range v.warnings {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", w)
 	}
 
 	return &v.resp, nil
 }
 
 type validator struct {
-	resp  plugin.CodeGeneratorResponse
-	files map[string]*desc.FileDescriptor
-	gapic *config.ConfigProto
+	resp              plugin.CodeGeneratorResponse
+	files             map[string]*desc.FileDescriptor
+	gapic             *config.ConfigProto
+	serviceConfig     *serviceconfig.Service
+	grpcServiceConfig *grpcServiceConfig
+	standardMethods   bool
+	baseline          map[string]*desc.FileDescriptor
+	changed           []string
+	extraWellKnown    wellKnownSet
+	warnings          []string
+
+	// idx is built from files on first use, see index
+	idx *fileIndex
+	// old validates the baseline, see baselineValidator
+	old *validator
 }
 
 // validate executes GAPIC configuration validation on the given
@@ -145,6 +323,15 @@ func (v *validator) validateService(serv *desc.ServiceDescriptor) {
 		v.addError(emptyDefaultHost, serv.GetFullyQualifiedName())
 	}
 
+	// validate google.cloud.operation_polling_method
+	if polling := pollingMethods(serv); len(polling) > 1 {
+		var names []string
+		for _, p := range polling {
+			names = append(names, strconv.Quote(p.GetName()))
+		}
+		v.addError(extOpMultiplePolling, serv.GetFullyQualifiedName(), strings.Join(names, ", "))
+	}
+
 	// validate Methods
 	for _, mthd := range serv.GetMethods() {
 		v.validateMethod(mthd)
@@ -162,22 +349,38 @@ func (v *validator) validateMethod(method *desc.MethodDescriptor) {
 		} else if eLRO, err := ext(opts, longrunning.E_OperationInfo); err != nil {
 			v.addError(missingLROInfo, mFQN)
 		} else {
-			lro := eLRO.(*longrunning.OperationInfo)
+			v.validateOperationInfo(eLRO.(*longrunning.OperationInfo), method)
+		}
+	}
 
-			if res := lro.GetResponseType(); res == "" {
-				v.addError(missingLROResponseType, mFQN)
-			} else if v.resolveMsgReference(res, method.GetFile()) == nil {
-				v.addError(unresolvableLROResponseType, res, mFQN)
-			}
+	// validate google.cloud.operation_service
+	if eServ, err := ext(method.GetMethodOptions(), extendedops.E_OperationService); err == nil {
+		v.validateOperationService(*eServ.(*string), method)
+	}
 
-			if meta := lro.GetMetadataType(); meta == "" {
-				v.addError(missingLROMetadataType, mFQN)
-			} else if v.resolveMsgReference(meta, method.GetFile()) == nil {
-				v.addError(unresolvableLROMetadataType, meta, mFQN)
-			}
-		}
+	// validate google.cloud.operation_polling_method
+	if isPollingMethod(method) {
+		v.validatePollingMethod(method)
 	}
 
+	// validate streaming
+	if method.IsClientStreaming() || method.IsServerStreaming() {
+		v.validateStreaming(method)
+	}
+
+	// validate google.api.http
+	if eHTTP, err := ext(method.GetMethodOptions(), annotations.E_Http); err == nil {
+		v.validateHTTPRule(eHTTP.(*annotations.HttpRule), method, fmt.Sprintf("rpc %q google.api.http", mFQN))
+	}
+
+	// validate AIP standard method shapes, if enabled
+	if v.standardMethods {
+		v.validateStandardMethod(method)
+	}
+
+	// validate AIP-158 pagination
+	v.validatePagination(method)
+
 	// validate google.api.method_signature
 	if eSig, err := ext(method.GetMethodOptions(), annotations.E_MethodSignature); err == nil {
 		sigs := eSig.([]string)
@@ -233,10 +436,416 @@ func (v *validator) validateMethod(method *desc.MethodDescriptor) {
 				}
 			}
 		}
+	} else if !method.IsClientStreaming() {
+		v.suggestMethodSignature(method)
+	}
+}
+
+// maxSuggestedSignatureFields is the most REQUIRED fields a request may have
+// for suggestMethodSignature to recommend flattening all of them.
+const maxSuggestedSignatureFields = 3
+
+// suggestMethodSignature warns about a method with no method_signature whose
+// request is a resource name or parent plus a few other REQUIRED fields,
+// suggesting a signature of the REQUIRED fields in declaration order.
+func (v *validator) suggestMethodSignature(method *desc.MethodDescriptor) {
+	var required []string
+	var resource bool
+	for _, field := range method.GetInputType().GetFields() {
+		if !isRequired(field) {
+			continue
+		}
+
+		required = append(required, field.GetName())
+		resource = resource || field.GetName() == "name" || field.GetName() == "parent"
+	}
+
+	if !resource || len(required) > maxSuggestedSignatureFields {
+		return
+	}
+
+	v.addWarning(sigSuggestion, method.GetFullyQualifiedName(), strings.Join(required, ","))
+}
+
+// validateOperationInfo ensures that the operation_info response_type and
+// metadata_type are present, resolvable and sensible for the method.
+func (v *validator) validateOperationInfo(lro *longrunning.OperationInfo, method *desc.MethodDescriptor) {
+	mFQN := method.GetFullyQualifiedName()
+
+	var resMsg, metaMsg *desc.MessageDescriptor
+	var resErr, metaErr error
+	res, meta := lro.GetResponseType(), lro.GetMetadataType()
+
+	if res == "" {
+		v.addError(missingLROResponseType, mFQN)
+	} else if resMsg, resErr = v.resolveMsgReference(res, method.GetFile()); isUnqualifiedEmpty(res, resMsg) {
+		v.addError(lroEmptyNotQualified, mFQN, "response_type", res)
+	} else if _, ok := resErr.(resolveWarning); ok {
+		v.addWarning(lroTypeResolution, "response_type", res, mFQN, resErr)
+	} else if resErr != nil {
+		v.addError(lroTypeResolution, "response_type", res, mFQN, resErr)
+	} else if resMsg == nil {
+		v.addError(unresolvableLROResponseType, res, mFQN)
+	} else if resMsg.GetFullyQualifiedName() == "google.longrunning.Operation" {
+		v.addError(lroResponseIsOperation, mFQN)
+	}
+
+	if meta == "" {
+		v.addError(missingLROMetadataType, mFQN)
+	} else if metaMsg, metaErr = v.resolveMsgReference(meta, method.GetFile()); isUnqualifiedEmpty(meta, metaMsg) {
+		v.addError(lroEmptyNotQualified, mFQN, "metadata_type", meta)
+	} else if _, ok := metaErr.(resolveWarning); ok {
+		v.addWarning(lroTypeResolution, "metadata_type", meta, mFQN, metaErr)
+	} else if metaErr != nil {
+		v.addError(lroTypeResolution, "metadata_type", meta, mFQN, metaErr)
+	} else if metaMsg == nil {
+		v.addError(unresolvableLROMetadataType, meta, mFQN)
+	}
+
+	if resMsg != nil && metaMsg != nil && resMsg.GetFullyQualifiedName() == metaMsg.GetFullyQualifiedName() {
+		v.addError(lroMetadataIsResponse, mFQN, resMsg.GetFullyQualifiedName())
+	}
+}
+
+// validatePagination ensures that a method which looks like an AIP-158
+// paginated method, by having any of the page_size, page_token or
+// next_page_token fields, has all of them with the expected types, as well
+// as a single repeated field to page over. Per AIP-4233, max_results may
+// stand in for page_size. Generators silently skip paging for methods that
+// don't quite match.
+func (v *validator) validatePagination(method *desc.MethodDescriptor) {
+	if method.IsClientStreaming() || method.IsServerStreaming() {
+		return
+	}
+
+	if !looksPaginated(method) {
+		return
+	}
+
+	mFQN := method.GetFullyQualifiedName()
+	in, out := method.GetInputType(), method.GetOutputType()
+	size := in.FindFieldByName("page_size")
+	token := in.FindFieldByName("page_token")
+	next := out.FindFieldByName("next_page_token")
+
+	type pageField struct {
+		msg   *desc.MessageDescriptor
+		name  string
+		field *desc.FieldDescriptor
+		typ   descriptor.FieldDescriptorProto_Type
+	}
+	var fields []pageField
+
+	// AIP-4233 lets max_results stand in for page_size
+	if maxResults := in.FindFieldByName("max_results"); size == nil && maxResults != nil {
+		v.validateMaxResults(mFQN, maxResults)
+	} else {
+		fields = append(fields, pageField{in, "page_size", size, descriptor.FieldDescriptorProto_TYPE_INT32})
+	}
+	fields = append(fields,
+		pageField{in, "page_token", token, descriptor.FieldDescriptorProto_TYPE_STRING},
+		pageField{out, "next_page_token", next, descriptor.FieldDescriptorProto_TYPE_STRING},
+	)
+
+	for _, pf := range fields {
+		if pf.field == nil {
+			v.addError(pageMissingField, mFQN, pf.msg.GetFullyQualifiedName(), pf.name)
+		} else if pf.field.GetType() != pf.typ || pf.field.IsRepeated() {
+			want := strings.ToLower(strings.TrimPrefix(pf.typ.String(), "TYPE_"))
+			got := typeName(pf.field)
+			if pf.field.IsRepeated() {
+				got = "repeated " + got
+			}
+			v.addError(pageFieldType, mFQN, pf.field.GetFullyQualifiedName(), want, got)
+		}
+	}
+
+	var results []*desc.FieldDescriptor
+	for _, field := range out.GetFields() {
+		// AIP-217 unreachable locations accompany the paged results
+		if field.GetName() == "unreachable" && field.GetType() == descriptor.FieldDescriptorProto_TYPE_STRING {
+			continue
+		}
+
+		if field.IsRepeated() {
+			results = append(results, field)
+		}
+	}
+
+	switch len(results) {
+	case 0:
+		v.addError(pageNoResults, mFQN, out.GetFullyQualifiedName())
+	case 1:
+		if results[0].IsMap() {
+			v.addError(pageMapResults, mFQN, out.GetFullyQualifiedName(), results[0].GetName())
+		}
+	default:
+		var names []string
+		for _, f := range results {
+			names = append(names, strconv.Quote(f.GetName()))
+		}
+		v.addError(pageMultipleResults, mFQN, out.GetFullyQualifiedName(), strings.Join(names, ", "))
+	}
+}
+
+// validateMaxResults ensures that the AIP-4233 max_results field standing in
+// for page_size is a singular int32 or uint32, or one of their wrapper types.
+func (v *validator) validateMaxResults(mFQN string, field *desc.FieldDescriptor) {
+	switch typeName(field) {
+	case "int32", "uint32", "google.protobuf.Int32Value", "google.protobuf.UInt32Value":
+		if !field.IsRepeated() {
+			return
+		}
+	}
+
+	got := typeName(field)
+	if field.IsRepeated() {
+		got = "repeated " + got
+	}
+	v.addError(pageFieldType, mFQN, field.GetFullyQualifiedName(), "int32, uint32 or a wrapper of either", got)
+}
+
+// looksPaginated reports if the method has any of the AIP-158 pagination
+// fields page_size, page_token or next_page_token.
+func looksPaginated(method *desc.MethodDescriptor) bool {
+	in, out := method.GetInputType(), method.GetOutputType()
+
+	return in.FindFieldByName("page_size") != nil ||
+		in.FindFieldByName("page_token") != nil ||
+		out.FindFieldByName("next_page_token") != nil
+}
+
+// validateStreaming ensures that a streaming method does not carry
+// configuration that generators cannot support for its kind of streaming.
+func (v *validator) validateStreaming(method *desc.MethodDescriptor) {
+	mFQN := method.GetFullyQualifiedName()
+	opts := method.GetMethodOptions()
+
+	kind := "server-streaming"
+	if method.IsClientStreaming() && method.IsServerStreaming() {
+		kind = "bidi-streaming"
+	} else if method.IsClientStreaming() {
+		kind = "client-streaming"
+	}
+
+	if _, err := ext(opts, longrunning.E_OperationInfo); err == nil {
+		v.addError(streamOperationInfo, kind, mFQN)
+	}
+
+	if !method.IsClientStreaming() {
+		if looksPaginated(method) {
+			v.addError(streamPaginated, mFQN)
+		}
+
+		return
+	}
+
+	if _, err := ext(opts, annotations.E_MethodSignature); err == nil {
+		v.addError(streamMethodSignature, kind, mFQN)
+	}
+
+	if _, err := ext(opts, annotations.E_Http); err == nil {
+		v.addError(streamHTTP, kind, mFQN)
+	}
+}
+
+// validateOperationService ensures that a method starting a non-standard,
+// or extended, LRO refers to a polling service that can poll the custom
+// operation it returns, and that its request field mappings resolve.
+func (v *validator) validateOperationService(name string, method *desc.MethodDescriptor) {
+	mFQN := method.GetFullyQualifiedName()
+	out := method.GetOutputType()
+
+	if out.GetFullyQualifiedName() == "google.longrunning.Operation" {
+		v.addError(extOpServiceWithLRO, mFQN)
+		return
+	}
+
+	if !isCustomOperation(out) {
+		v.addError(extOpNotCustomOperation, mFQN, out.GetFullyQualifiedName())
+	}
+
+	serv := v.resolveServiceReference(name, method.GetFile())
+	if serv == nil {
+		v.addError(extOpServiceDNE, mFQN, name)
+		return
+	}
+
+	polling := pollingMethods(serv)
+	if len(polling) == 0 {
+		v.addError(extOpNoPollingMethod, serv.GetFullyQualifiedName(), mFQN)
+		return
+	} else if len(polling) > 1 {
+		// reported when validating the polling service
+		return
+	}
+
+	poll := polling[0]
+	if pOut := poll.GetOutputType(); pOut.GetFullyQualifiedName() != out.GetFullyQualifiedName() {
+		v.addError(
+			extOpPollingResponse,
+			poll.GetFullyQualifiedName(),
+			out.GetFullyQualifiedName(),
+			mFQN,
+			pOut.GetFullyQualifiedName(),
+		)
+	}
+
+	pIn := poll.GetInputType()
+	for _, field := range method.GetInputType().GetFields() {
+		eReq, err := ext(field.GetFieldOptions(), extendedops.E_OperationRequestField)
+		if err != nil {
+			continue
+		}
+
+		if target := *eReq.(*string); pIn.FindFieldByName(target) == nil {
+			v.addError(extOpRequestFieldDNE, field.GetFullyQualifiedName(), target, pIn.GetFullyQualifiedName())
+		}
+	}
+}
+
+// validatePollingMethod ensures that a method annotated as an extended LRO
+// polling method returns a custom operation and that its request maps fields
+// from that operation.
+func (v *validator) validatePollingMethod(method *desc.MethodDescriptor) {
+	mFQN := method.GetFullyQualifiedName()
+	in, out := method.GetInputType(), method.GetOutputType()
+
+	if !isCustomOperation(out) {
+		v.addError(extOpPollingNotCustom, mFQN, out.GetFullyQualifiedName())
+	}
+
+	var mapped bool
+	for _, field := range in.GetFields() {
+		eRes, err := ext(field.GetFieldOptions(), extendedops.E_OperationResponseField)
+		if err != nil {
+			continue
+		}
+		mapped = true
+
+		if target := *eRes.(*string); out.FindFieldByName(target) == nil {
+			v.addError(extOpResponseFieldDNE, field.GetFullyQualifiedName(), target, out.GetFullyQualifiedName())
+		}
+	}
+
+	if !mapped {
+		v.addError(extOpPollingNoMapping, mFQN, in.GetFullyQualifiedName())
+	}
+}
+
+// validateOperationFields ensures that a custom operation message, one with
+// fields annotated google.cloud.operation_field, annotates exactly one field
+// of the appropriate type for each of the standard Operation fields.
+func (v *validator) validateOperationFields(msg *desc.MessageDescriptor) {
+	fields := map[extendedops.OperationResponseMapping][]*desc.FieldDescriptor{}
+	for _, field := range msg.GetFields() {
+		if eOp, err := ext(field.GetFieldOptions(), extendedops.E_OperationField); err == nil {
+			mapping := *eOp.(*extendedops.OperationResponseMapping)
+			fields[mapping] = append(fields[mapping], field)
+		}
+	}
+
+	if len(fields) == 0 {
+		return
+	}
+
+	for _, field := range fields[extendedops.OperationResponseMapping_UNDEFINED] {
+		v.addError(extOpFieldUndefined, field.GetFullyQualifiedName())
+	}
+
+	for _, mapping := range []extendedops.OperationResponseMapping{
+		extendedops.OperationResponseMapping_NAME,
+		extendedops.OperationResponseMapping_STATUS,
+		extendedops.OperationResponseMapping_ERROR_CODE,
+		extendedops.OperationResponseMapping_ERROR_MESSAGE,
+	} {
+		switch fs := fields[mapping]; len(fs) {
+		case 0:
+			v.addError(extOpFieldMissing, msg.GetFullyQualifiedName(), mapping)
+		case 1:
+			if want, ok := operationFieldType(mapping, fs[0]); !ok {
+				v.addError(extOpFieldType, fs[0].GetFullyQualifiedName(), mapping, want, typeName(fs[0]))
+			}
+		default:
+			var names []string
+			for _, f := range fs {
+				names = append(names, strconv.Quote(f.GetName()))
+			}
+			v.addError(extOpFieldDuplicate, msg.GetFullyQualifiedName(), mapping, strings.Join(names, ", "))
+		}
+	}
+}
+
+// operationFieldType reports if the field has a type generators can map to
+// the given standard Operation field, along with a description of the
+// expected type.
+func operationFieldType(mapping extendedops.OperationResponseMapping, field *desc.FieldDescriptor) (string, bool) {
+	if field.IsRepeated() {
+		return "singular", false
+	}
+
+	typ := field.GetType()
+	switch mapping {
+	case extendedops.OperationResponseMapping_NAME, extendedops.OperationResponseMapping_ERROR_MESSAGE:
+		return "string", typ == descriptor.FieldDescriptorProto_TYPE_STRING
+	case extendedops.OperationResponseMapping_ERROR_CODE:
+		return "int32", typ == descriptor.FieldDescriptorProto_TYPE_INT32
+	case extendedops.OperationResponseMapping_STATUS:
+		return "an enum or bool", typ == descriptor.FieldDescriptorProto_TYPE_ENUM || typ == descriptor.FieldDescriptorProto_TYPE_BOOL
+	}
+
+	return "", true
+}
+
+// isCustomOperation reports if the message has any fields annotated
+// google.cloud.operation_field.
+func isCustomOperation(msg *desc.MessageDescriptor) bool {
+	for _, field := range msg.GetFields() {
+		if _, err := ext(field.GetFieldOptions(), extendedops.E_OperationField); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isPollingMethod reports if the method is annotated
+// google.cloud.operation_polling_method = true.
+func isPollingMethod(method *desc.MethodDescriptor) bool {
+	ePoll, err := ext(method.GetMethodOptions(), extendedops.E_OperationPollingMethod)
+	return err == nil && *ePoll.(*bool)
+}
+
+// pollingMethods returns the methods of the service annotated
+// google.cloud.operation_polling_method = true.
+func pollingMethods(serv *desc.ServiceDescriptor) []*desc.MethodDescriptor {
+	var polling []*desc.MethodDescriptor
+	for _, mthd := range serv.GetMethods() {
+		if isPollingMethod(mthd) {
+			polling = append(polling, mthd)
+		}
+	}
+
+	return polling
+}
+
+// isUnqualifiedEmpty reports if name refers to google.protobuf.Empty, or
+// failed to resolve as a bare "Empty", without being fully qualified.
+func isUnqualifiedEmpty(name string, msg *desc.MessageDescriptor) bool {
+	if name == "google.protobuf.Empty" || name == ".google.protobuf.Empty" {
+		return false
 	}
+
+	if msg == nil {
+		return name == "Empty"
+	}
+
+	return msg.GetFullyQualifiedName() == "google.protobuf.Empty"
 }
 
 func (v *validator) validateMessage(msg *desc.MessageDescriptor) {
+	var nameField *desc.FieldDescriptor
+
 	// validate message resource
 	if eRes, err := ext(msg.GetMessageOptions(), annotations.E_Resource); err == nil {
 		res := eRes.(*annotations.ResourceDescriptor)
@@ -263,19 +872,168 @@ func (v *validator) validateMessage(msg *desc.MessageDescriptor) {
 		if f := msg.FindFieldByName(fname); f == nil && !isSingleton {
 			// missing resource name field
 			v.addError(resMissingNameField, msg.GetFullyQualifiedName())
+		} else if f != nil {
+			v.validateNameField(f)
+			nameField = f
+		}
+
+		for _, style := range res.GetStyle() {
+			if style == annotations.ResourceDescriptor_DECLARATIVE_FRIENDLY {
+				v.validateDeclarativeFriendly(res, msg)
+			}
 		}
 	}
 
+	// validate custom operation fields
+	v.validateOperationFields(msg)
+
 	for _, field := range msg.GetFields() {
 		// validate individual resource reference
 		if eRef, err := ext(field.GetFieldOptions(), annotations.E_ResourceReference); err == nil {
+			if field.GetType() != descriptor.FieldDescriptorProto_TYPE_STRING {
+				v.addError(resRefNotString, field.GetFullyQualifiedName(), typeName(field))
+			}
+
+			if field == nameField {
+				v.addWarning(resRefOnNameField, field.GetFullyQualifiedName(), msg.GetFullyQualifiedName())
+			}
+
 			v.validateResRef(eRef.(*annotations.ResourceReference), field)
 		}
+
+		// validate field format
+		if eInfo, err := ext(field.GetFieldOptions(), annotations.E_FieldInfo); err == nil {
+			v.validateFieldInfo(eInfo.(*annotations.FieldInfo), field)
+		}
+	}
+}
+
+// validateFieldInfo ensures that the google.api.field_info format is applied
+// to a field that generators can auto-populate or validate.
+func (v *validator) validateFieldInfo(info *annotations.FieldInfo, field *desc.FieldDescriptor) {
+	format := info.GetFormat()
+	if format == annotations.FieldInfo_FORMAT_UNSPECIFIED {
+		return
+	}
+	fqn := field.GetFullyQualifiedName()
+
+	if field.GetType() != descriptor.FieldDescriptorProto_TYPE_STRING {
+		v.addError(fieldInfoNotString, fqn, format)
+	}
+
+	if field.IsRepeated() && !repeatedFormats[format] {
+		v.addError(fieldInfoRepeated, fqn, format)
+	}
+
+	if format != annotations.FieldInfo_UUID4 || !v.autoPopulatable(field) {
+		return
+	}
+
+	var behavior []annotations.FieldBehavior
+	if eBehv, err := ext(field.GetFieldOptions(), annotations.E_FieldBehavior); err == nil {
+		behavior = eBehv.([]annotations.FieldBehavior)
+	}
+
+	if containBehavior(behavior, annotations.FieldBehavior_REQUIRED) {
+		v.addError(fieldInfoUUID4Required, fqn)
+	} else if !containBehavior(behavior, annotations.FieldBehavior_OPTIONAL) {
+		v.addError(fieldInfoUUID4NotOptional, fqn)
+	}
+}
+
+// autoPopulatable reports if generators may auto-populate the field as a
+// request ID: it is listed in a publishing method_settings
+// auto_populated_fields, or it is a singular string field of a method's
+// request message that is not OUTPUT_ONLY.
+func (v *validator) autoPopulatable(field *desc.FieldDescriptor) bool {
+	owner := field.GetOwner().GetFullyQualifiedName()
+
+	for _, ms := range v.serviceConfig.GetPublishing().GetMethodSettings() {
+		method := v.resolveMethod(ms.GetSelector())
+		if method != nil && method.GetInputType().GetFullyQualifiedName() == owner && containStr(ms.GetAutoPopulatedFields(), field.GetName()) {
+			return true
+		}
+	}
+
+	if field.GetType() != descriptor.FieldDescriptorProto_TYPE_STRING || field.IsRepeated() {
+		return false
+	}
+
+	if eBehv, err := ext(field.GetFieldOptions(), annotations.E_FieldBehavior); err == nil &&
+		containBehavior(eBehv.([]annotations.FieldBehavior), annotations.FieldBehavior_OUTPUT_ONLY) {
+		return false
+	}
+
+	return v.index().inputs[owner]
+}
+
+// validateNameField ensures that a resource's name field is a singular
+// string field outside of a oneof with a non-conflicting field_behavior:
+// never REQUIRED, nor both IDENTIFIER and OUTPUT_ONLY.
+func (v *validator) validateNameField(field *desc.FieldDescriptor) {
+	fqn := field.GetFullyQualifiedName()
+
+	if field.GetType() != descriptor.FieldDescriptorProto_TYPE_STRING {
+		v.addError(resNameFieldNotString, fqn)
+	}
+
+	if field.IsRepeated() {
+		v.addError(resNameFieldRepeated, fqn)
+	}
+
+	if oneof := field.GetOneOf(); oneof != nil && !oneof.IsSynthetic() {
+		v.addError(resNameFieldInOneof, fqn, oneof.GetName())
+	}
+
+	eBehv, err := ext(field.GetFieldOptions(), annotations.E_FieldBehavior)
+	if err != nil {
+		return
+	}
+	behavior := eBehv.([]annotations.FieldBehavior)
+
+	ident := containBehavior(behavior, annotations.FieldBehavior_IDENTIFIER)
+
+	if containBehavior(behavior, annotations.FieldBehavior_REQUIRED) {
+		v.addError(resNameFieldConflict, fqn, annotations.FieldBehavior_REQUIRED)
+	} else if !ident {
+		// OUTPUT_ONLY alone is the convention that predates IDENTIFIER
+		v.addWarning(resNameFieldNotIdent, fqn)
+	}
+
+	if ident && containBehavior(behavior, annotations.FieldBehavior_OUTPUT_ONLY) {
+		v.addError(resNameFieldIdentOutput, fqn)
+	}
+}
+
+// validateDeclarativeFriendly ensures that a resource with style
+// DECLARATIVE_FRIENDLY has an etag, and that the standard methods mutating
+// it support validate_only.
+func (v *validator) validateDeclarativeFriendly(res *annotations.ResourceDescriptor, msg *desc.MessageDescriptor) {
+	if f := msg.FindFieldByName("etag"); f == nil || f.GetType() != descriptor.FieldDescriptorProto_TYPE_STRING {
+		v.addError(resDeclMissingEtag, msg.GetFullyQualifiedName())
+	}
+
+	typ := res.GetType()
+	kind := typ[strings.Index(typ, "/")+1:]
+	for _, f := range v.sortedFiles() {
+		for _, serv := range f.GetServices() {
+			for _, prefix := range []string{"Create", "Update", "Delete"} {
+				method := serv.FindMethodByName(prefix + kind)
+				if method == nil {
+					continue
+				}
+
+				input := method.GetInputType()
+				if f := input.FindFieldByName("validate_only"); f == nil || f.GetType() != descriptor.FieldDescriptorProto_TYPE_BOOL {
+					v.addError(resDeclMissingValidate, method.GetFullyQualifiedName(), typ, input.GetFullyQualifiedName())
+				}
+			}
+		}
 	}
 }
 
 // validateResourceDescriptor validates the resource_type_kind and pattern
-// presence of a given ResourceDescriptor for the owner with the
+// syntax of a given ResourceDescriptor for the owner with the
 // fully-qualified name fqn.
 func (v *validator) validateResourceDescriptor(res *annotations.ResourceDescriptor, fqn string) {
 	// missing resource.pattern
@@ -283,6 +1041,30 @@ func (v *validator) validateResourceDescriptor(res *annotations.ResourceDescript
 		v.addError(resMissingPattern, fqn)
 	}
 
+	// malformed resource.pattern
+	var pats []*resourcePattern
+	for _, pat := range res.GetPattern() {
+		p, err := parsePattern(pat)
+		if err != nil {
+			v.addError(resInvalidPattern, fqn, pat, err)
+			continue
+		}
+		pats = append(pats, p)
+	}
+	v.validatePatternConsistency(res, pats, fqn)
+
+	// validate resource.history against the pattern count
+	switch n := len(res.GetPattern()); res.GetHistory() {
+	case annotations.ResourceDescriptor_FUTURE_MULTI_PATTERN:
+		if n != 1 {
+			v.addError(resFutureMultiPattern, fqn, n)
+		}
+	case annotations.ResourceDescriptor_ORIGINALLY_SINGLE_PATTERN:
+		if n < 2 {
+			v.addError(resOrigSinglePattern, fqn, n)
+		}
+	}
+
 	// missing resource.type
 	typ := res.GetType()
 	if typ == "" {
@@ -298,6 +1080,183 @@ func (v *validator) validateResourceDescriptor(res *annotations.ResourceDescript
 	}
 
 	v.validateRescTypeKind(split[1], fqn)
+
+	// validate resource.singular & resource.plural
+	singular, plural := res.GetSingular(), res.GetPlural()
+	if want := lowerFirst(split[1]); singular != "" && singular != want {
+		v.addError(resSingularNotTypeKind, fqn, singular, want)
+	}
+
+	if plural != "" {
+		if !lowerCamelRegexp.MatchString(plural) {
+			v.addError(resPluralInvalid, fqn, plural)
+		}
+
+		if plural == singular {
+			v.addError(resPluralIsSingular, fqn, plural)
+		}
+	}
+}
+
+// validatePatternConsistency ensures that all of a resource's patterns end
+// with the same variable, or, for singletons, the same literal segment, and
+// that singular and plural, when set, agree with the final variable and
+// collection identifier of each pattern. Per AIP-122 a nested collection
+// identifier may be shortened, e.g. "events" in "users/{user}/events/{event}"
+// for plural "userEvents". Sentinel patterns like "_deleted-topic_" are not
+// resource name templates and are skipped.
+func (v *validator) validatePatternConsistency(res *annotations.ResourceDescriptor, all []*resourcePattern, fqn string) {
+	var pats []*resourcePattern
+	for _, p := range all {
+		if !p.isSentinel() {
+			pats = append(pats, p)
+		}
+	}
+	if len(pats) == 0 {
+		return
+	}
+
+	first := pats[0]
+	for _, p := range pats[1:] {
+		switch {
+		case p.isSingleton() != first.isSingleton():
+			singleton, other := first, p
+			if p.isSingleton() {
+				singleton, other = p, first
+			}
+			v.addError(resSingletonMismatch, fqn, singleton.raw, other.raw)
+		case p.isSingleton():
+			if p.lastSegment() != first.lastSegment() {
+				v.addError(resSingletonLiteral, fqn, first.raw, first.lastSegment(), p.raw, p.lastSegment())
+			}
+		case p.lastVariable() != first.lastVariable():
+			v.addError(resPatternVarMismatch, fqn, first.raw, first.lastVariable(), p.raw, p.lastVariable())
+		}
+	}
+
+	if plural := res.GetPlural(); plural != "" {
+		for _, p := range pats {
+			if coll := p.collection(); coll != "" && coll != plural && !strings.HasSuffix(plural, upperFirst(coll)) {
+				v.addError(resPluralMismatch, fqn, plural, coll, p.raw)
+			}
+		}
+	}
+
+	if singular := res.GetSingular(); singular != "" {
+		for _, p := range pats {
+			if p.isSingleton() {
+				if seg := p.lastSegment(); seg != singular && !strings.HasSuffix(singular, upperFirst(seg)) {
+					v.addError(resSingularMismatch, fqn, singular, seg, p.raw)
+				}
+			} else if last := p.lastVariable(); !matchesVariable(singular, last) {
+				v.addError(resSingularMismatch, fqn, singular, "{"+last+"}", p.raw)
+			}
+		}
+	}
+}
+
+// matchesVariable reports if the lowerCamelCase singular corresponds to the
+// lower_snake_case pattern variable, e.g. "bookShelf" and "book_shelf". As
+// with plural, a nested variable may be shortened, e.g. "event" for
+// "userEvent".
+func matchesVariable(singular, variable string) bool {
+	snake := camelToSnake(singular)
+	return snake == variable || strings.HasSuffix(snake, "_"+variable)
+}
+
+// validateResourceUniqueness reports resource types with conflicting
+// definitions across the file set, and patterns claimed by more than one
+// resource type. Only conflicts involving a file in gen are reported.
+// Identical resource_definitions of the same type, commonly used to
+// declare a shared resource locally, are not considered conflicting. A
+// message redefined by an identical resource_definition is only warned
+// about, as references may resolve to either.
+func (v *validator) validateResourceUniqueness(gen map[string]bool) {
+	var types, patterns []string
+	byType := map[string][]resourceDef{}
+	byPattern := map[string][]resourceDef{}
+
+	for _, d := range v.resourceDefs() {
+		typ := d.res.GetType()
+		if typ == "" {
+			continue
+		}
+
+		if _, ok := byType[typ]; !ok {
+			types = append(types, typ)
+		}
+		byType[typ] = append(byType[typ], d)
+
+		for _, pat := range d.res.GetPattern() {
+			if _, ok := byPattern[pat]; !ok {
+				patterns = append(patterns, pat)
+			}
+			byPattern[pat] = append(byPattern[pat], d)
+		}
+	}
+
+	for _, typ := range types {
+		defs := byType[typ]
+		if len(defs) < 2 || !inFiles(defs, gen) {
+			continue
+		}
+
+		var msgs int
+		conflict := false
+		for _, d := range defs {
+			if d.msg != nil {
+				msgs++
+			}
+			if strings.Join(d.res.GetPattern(), ",") != strings.Join(defs[0].res.GetPattern(), ",") {
+				conflict = true
+			}
+		}
+		if !conflict && msgs == 0 {
+			continue
+		}
+
+		var locs []string
+		for _, d := range defs {
+			locs = append(locs, d.location())
+		}
+
+		if !conflict && msgs == 1 {
+			v.addWarning(resTypeRedefined, typ, strings.Join(locs, ", "))
+			continue
+		}
+		v.addError(resTypeConflict, typ, strings.Join(locs, ", "))
+	}
+
+	for _, pat := range patterns {
+		defs := byPattern[pat]
+		if !inFiles(defs, gen) {
+			continue
+		}
+
+		var claims []string
+		seen := map[string]bool{}
+		for _, d := range defs {
+			if typ := d.res.GetType(); !seen[typ] {
+				seen[typ] = true
+				claims = append(claims, fmt.Sprintf("%q (%s)", typ, d.location()))
+			}
+		}
+
+		if len(claims) > 1 {
+			v.addError(resPatternConflict, pat, strings.Join(claims, ", "))
+		}
+	}
+}
+
+// inFiles reports if any of the resource definitions are in the named files.
+func inFiles(defs []resourceDef, files map[string]bool) bool {
+	for _, d := range defs {
+		if files[d.file.GetName()] {
+			return true
+		}
+	}
+
+	return false
 }
 
 // validateRescTypeKind ensures that the resource_type_kind component
@@ -321,8 +1280,16 @@ func (v *validator) validateResRef(ref *annotations.ResourceReference, field *de
 		typ = ref.GetChildType()
 	}
 
-	// check well-known types
-	if wellKnownTypes[typ] || typ == "*" {
+	if typ == "*" {
+		return
+	}
+
+	// well-known types need not be defined in the file set, but the
+	// parent of a well-known child_type can still be checked
+	if v.isWellKnownType(typ) {
+		if ref.GetType() == "" {
+			v.validateChildType(typ, field)
+		}
 		return
 	}
 
@@ -335,7 +1302,65 @@ func (v *validator) validateResRef(ref *annotations.ResourceReference, field *de
 
 	if refMsg == nil {
 		v.addError(resRefNotValidResource, field.GetFullyQualifiedName(), typ)
+		return
+	}
+
+	if ref.GetType() == "" {
+		v.validateChildType(typ, field)
+	}
+}
+
+// validateChildType ensures that the parent of a child_type resource,
+// derived from the child's patterns, is itself a known resource.
+func (v *validator) validateChildType(child string, field *desc.FieldDescriptor) {
+	res := v.resolveResourceDescriptor(child)
+	if res == nil {
+		return
+	}
+
+	var parsed int
+	var parents []string
+	for _, pat := range res.GetPattern() {
+		// malformed patterns are reported on the resource itself
+		p, err := parsePattern(pat)
+		if err != nil {
+			continue
+		}
+		parsed++
+
+		if parent := p.parent(); parent != "" {
+			parents = append(parents, parent)
+		}
+	}
+
+	if parsed == 0 {
+		return
+	} else if len(parents) == 0 {
+		v.addError(resRefChildNoParent, child, field.GetFullyQualifiedName())
+		return
+	}
+
+	known := v.knownPatterns()
+	for _, parent := range parents {
+		if known[parent] {
+			return
+		}
 	}
+
+	v.addError(resRefChildNoKnown, child, field.GetFullyQualifiedName(), strings.Join(parents, ", "))
+}
+
+// typeName returns the proto type of the field as written in a .proto
+// file, e.g. "int64" or the fully-qualified name of a message.
+func typeName(field *desc.FieldDescriptor) string {
+	if msg := field.GetMessageType(); msg != nil {
+		return msg.GetFullyQualifiedName()
+	}
+	if enum := field.GetEnumType(); enum != nil {
+		return enum.GetFullyQualifiedName()
+	}
+
+	return strings.ToLower(strings.TrimPrefix(field.GetType().String(), "TYPE_"))
 }
 
 // addError adds the given validation error to the plugin response
@@ -351,6 +1376,16 @@ func (v *validator) addError(err string, info ...interface{}) {
 	v.resp.Error = proto.String(err)
 }
 
+// addWarning records the given non-fatal finding. Warnings are written
+// to stderr once validation completes and do not fail the plugin.
+func (v *validator) addWarning(warn string, info ...interface{}) {
+	if len(info) > 0 {
+		warn = fmt.Sprintf(warn, info...)
+	}
+
+	v.warnings = append(v.warnings, warn)
+}
+
 // ext wraps proto.GetExtension
 func ext(pb proto.Message, eDesc *proto.ExtensionDesc) (interface{}, error) {
 	return proto.GetExtension(pb, eDesc)
@@ -16,14 +16,24 @@ package validator
 
 import (
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
 	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/api/serviceconfig"
+	"google.golang.org/genproto/googleapis/cloud/extendedops"
 	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/protoc-gen-go/descriptor"
 	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/desc/builder"
 
@@ -316,6 +326,56 @@ func TestValidateMethod_MethodSignature(t *testing.T) {
 	}
 }
 
+func TestValidateMethod_SignatureSuggestion(t *testing.T) {
+	var v validator
+
+	required := func() *descriptor.FieldOptions {
+		return fieldOpts(t, annotations.E_FieldBehavior, []annotations.FieldBehavior{annotations.FieldBehavior_REQUIRED})
+	}
+
+	str := builder.FieldTypeString
+	create := builder.NewMessage("CreateBookRequest").
+		AddField(builder.NewField("parent", str()).SetOptions(required())).
+		AddField(builder.NewField("request_id", str())).
+		AddField(builder.NewField("book_id", str()).SetOptions(required()))
+	noResource := builder.NewMessage("SearchRequest").
+		AddField(builder.NewField("query", str()).SetOptions(required()))
+	tooMany := builder.NewMessage("MoveBookRequest").
+		AddField(builder.NewField("name", str()).SetOptions(required())).
+		AddField(builder.NewField("a", str()).SetOptions(required())).
+		AddField(builder.NewField("b", str()).SetOptions(required())).
+		AddField(builder.NewField("c", str()).SetOptions(required()))
+
+	sigOpts := methodOpts(t, annotations.E_MethodSignature, []string{"parent,book_id"})
+
+	rpc := builder.RpcTypeMessage
+	f, err := builder.NewFile("library.proto").SetPackageName("library").
+		AddMessage(create).AddMessage(noResource).AddMessage(tooMany).
+		AddService(builder.NewService("Library").
+			AddMethod(builder.NewMethod("CreateBook", rpc(create, false), rpc(create, false))).
+			AddMethod(builder.NewMethod("CreateBookFlattened", rpc(create, false), rpc(create, false)).SetOptions(sigOpts)).
+			AddMethod(builder.NewMethod("UploadBook", rpc(create, true), rpc(create, false))).
+			AddMethod(builder.NewMethod("Search", rpc(noResource, false), rpc(noResource, false))).
+			AddMethod(builder.NewMethod("MoveBook", rpc(tooMany, false), rpc(tooMany, false)))).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range f.FindService("library.Library").GetMethods() {
+		v.validateMethod(m)
+	}
+
+	if actual := v.resp.GetError(); actual != "" {
+		t.Errorf("validateMethod: got(%s) want()", actual)
+	}
+
+	want := []string{fmt.Sprintf(sigSuggestion, "library.Library.CreateBook", "parent,book_id")}
+	if fmt.Sprint(v.warnings) != fmt.Sprint(want) {
+		t.Errorf("validateMethod warnings: got(%v) want(%v)", v.warnings, want)
+	}
+}
+
 func TestValidateMessage(t *testing.T) {
 	var v validator
 
@@ -406,3 +466,2015 @@ func TestValidateMessage(t *testing.T) {
 		v.resp.Error = nil
 	}
 }
+
+func TestValidateMessage_FieldInfo(t *testing.T) {
+	var v validator
+
+	req := builder.NewMessage("Request").
+		AddField(builder.NewField("request_id", builder.FieldTypeString()).SetOptions(fieldInfoOpts(t, annotations.FieldInfo_UUID4, annotations.FieldBehavior_OPTIONAL))).
+		AddField(builder.NewField("ip", builder.FieldTypeString()).SetOptions(fieldInfoOpts(t, annotations.FieldInfo_IPV4))).
+		AddField(builder.NewField("ips", builder.FieldTypeString()).SetRepeated().SetOptions(fieldInfoOpts(t, annotations.FieldInfo_IPV4_OR_IPV6))).
+		AddField(builder.NewField("unspecified", builder.FieldTypeInt64()).SetOptions(fieldInfoOpts(t, annotations.FieldInfo_FORMAT_UNSPECIFIED)))
+	invalid := builder.NewMessage("Invalid").
+		AddField(builder.NewField("num", builder.FieldTypeInt64()).SetOptions(fieldInfoOpts(t, annotations.FieldInfo_IPV6))).
+		AddField(builder.NewField("ids", builder.FieldTypeString()).SetRepeated().SetOptions(fieldInfoOpts(t, annotations.FieldInfo_UUID4, annotations.FieldBehavior_OPTIONAL))).
+		AddField(builder.NewField("required_id", builder.FieldTypeString()).SetOptions(fieldInfoOpts(t, annotations.FieldInfo_UUID4, annotations.FieldBehavior_REQUIRED))).
+		AddField(builder.NewField("bare_id", builder.FieldTypeString()).SetOptions(fieldInfoOpts(t, annotations.FieldInfo_UUID4)))
+	// AIP-148 uid fields are not request IDs and are never auto-populated
+	resource := builder.NewMessage("Resource").
+		AddField(builder.NewField("uid", builder.FieldTypeString()).SetOptions(fieldInfoOpts(t, annotations.FieldInfo_UUID4, annotations.FieldBehavior_OUTPUT_ONLY))).
+		AddField(builder.NewField("other_id", builder.FieldTypeString()).SetOptions(fieldInfoOpts(t, annotations.FieldInfo_UUID4)))
+
+	rpc := builder.RpcTypeMessage
+	f, err := builder.NewFile("fieldinfo.proto").
+		AddMessage(req).AddMessage(invalid).AddMessage(resource).
+		AddService(builder.NewService("Service").
+			AddMethod(builder.NewMethod("Do", rpc(req, false), rpc(resource, false))).
+			AddMethod(builder.NewMethod("DoInvalid", rpc(invalid, false), rpc(resource, false)))).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"fieldinfo.proto": f}
+
+	for _, tst := range []struct {
+		name, want string
+		msg        *desc.MessageDescriptor
+	}{
+		{name: "valid field_info", want: "", msg: f.FindMessage("Request")},
+		{
+			name: "invalid field_info",
+			want: fmt.Sprintf("\n"+fieldInfoNotString+"\n"+fieldInfoRepeated+"\n"+fieldInfoUUID4Required+"\n"+fieldInfoUUID4NotOptional,
+				"Invalid.num", annotations.FieldInfo_IPV6,
+				"Invalid.ids", annotations.FieldInfo_UUID4,
+				"Invalid.required_id",
+				"Invalid.bare_id",
+			),
+			msg: f.FindMessage("Invalid"),
+		},
+		{name: "non-request uid", want: "", msg: f.FindMessage("Resource")},
+	} {
+		v.validateMessage(tst.msg)
+
+		if actual := v.resp.GetError(); actual != tst.want {
+			t.Errorf("%s: got(%s) want(%s)", tst.name, actual, tst.want)
+		}
+
+		// reset resp.Error field between tests
+		v.resp.Error = nil
+	}
+}
+
+func TestParsePattern(t *testing.T) {
+	for _, tst := range []struct {
+		pattern, want string
+	}{
+		{pattern: "projects/{project}/books/{book}", want: ""},
+		{pattern: "projects/{project}/books/{book_id}~{shelf_id}", want: ""},
+		{pattern: "projects/{project}/settings", want: ""},
+		{pattern: "", want: "position 0: pattern is empty"},
+		{pattern: "/projects/{project}", want: "position 0: leading '/' is not allowed"},
+		{pattern: "projects/{project}/", want: "position 18: trailing '/' is not allowed"},
+		{pattern: "projects//{project}", want: "position 9: empty segment"},
+		{pattern: "projects/{project", want: "position 9: unbalanced '{'"},
+		{pattern: "projects/project}", want: "position 16: unbalanced '}'"},
+		{pattern: "projects/{pro{ject}}", want: "position 13: nested '{' is not allowed"},
+		{pattern: "projects/{}", want: "position 9: variable name is empty"},
+		{pattern: "projects/{project=**}", want: `position 9: variable captures like "{project=**}" are not supported`},
+		{pattern: "projects/{projectId}", want: `position 10: variable "projectId" must be lower snake_case`},
+		{pattern: "projects/{project}/projects/{project}", want: `position 29: duplicate variable "project"`},
+		{pattern: "books/{book}{shelf}", want: "position 12: adjacent variables must be separated by a literal"},
+	} {
+		var got string
+		if _, err := parsePattern(tst.pattern); err != nil {
+			got = err.Error()
+		}
+
+		if got != tst.want {
+			t.Errorf("parsePattern(%q): got(%s) want(%s)", tst.pattern, got, tst.want)
+		}
+	}
+}
+
+func TestValidateResourceDescriptor(t *testing.T) {
+	var v validator
+	resourceTypeKindRegexp = regexp.MustCompile("[A-Z][a-zA-Z0-9]+")
+
+	for _, tst := range []struct {
+		name, want string
+		res        *annotations.ResourceDescriptor
+	}{
+		{
+			name: "valid multi-pattern",
+			want: "",
+			res: &annotations.ResourceDescriptor{
+				Type:     "foo.googleapis.com/BookShelf",
+				Pattern:  []string{"projects/{project}/bookShelves/{book_shelf}", "folders/{folder}/bookShelves/{book_shelf}"},
+				Singular: "bookShelf",
+				Plural:   "bookShelves",
+			},
+		},
+		{
+			name: "malformed pattern",
+			want: fmt.Sprintf("\n"+resInvalidPattern, "foo.Book", "projects/{project}/books/{book=**}", `position 25: variable captures like "{book=**}" are not supported`),
+			res: &annotations.ResourceDescriptor{
+				Type:    "foo.googleapis.com/Book",
+				Pattern: []string{"projects/{project}/books/{book}", "projects/{project}/books/{book=**}"},
+			},
+		},
+		{
+			name: "mismatched last variable",
+			want: fmt.Sprintf("\n"+resPatternVarMismatch, "foo.Book", "projects/{project}/books/{book}", "book", "folders/{folder}/books/{book_id}", "book_id"),
+			res: &annotations.ResourceDescriptor{
+				Type:    "foo.googleapis.com/Book",
+				Pattern: []string{"projects/{project}/books/{book}", "folders/{folder}/books/{book_id}"},
+			},
+		},
+		{
+			name: "singleton with several parents",
+			want: "",
+			res: &annotations.ResourceDescriptor{
+				Type:    "foo.googleapis.com/Settings",
+				Pattern: []string{"projects/{project}/settings", "folders/{folder}/settings"},
+			},
+		},
+		{
+			name: "mismatched singleton segment",
+			want: fmt.Sprintf("\n"+resSingletonLiteral, "foo.Book", "projects/{project}/settings", "settings", "folders/{folder}/config", "config"),
+			res: &annotations.ResourceDescriptor{
+				Type:    "foo.googleapis.com/Settings",
+				Pattern: []string{"projects/{project}/settings", "folders/{folder}/config"},
+			},
+		},
+		{
+			name: "mixed singleton",
+			want: fmt.Sprintf("\n"+resSingletonMismatch, "foo.Book", "projects/{project}/settings", "settings/{setting}"),
+			res: &annotations.ResourceDescriptor{
+				Type:    "foo.googleapis.com/Settings",
+				Pattern: []string{"settings/{setting}", "projects/{project}/settings"},
+			},
+		},
+		{
+			name: "shortened nested collection",
+			want: "",
+			res: &annotations.ResourceDescriptor{
+				Type:     "foo.googleapis.com/UserEvent",
+				Pattern:  []string{"users/{user}/events/{event}"},
+				Singular: "userEvent",
+				Plural:   "userEvents",
+			},
+		},
+		{
+			name: "mismatched singular & plural",
+			want: fmt.Sprintf("\n"+resPluralMismatch+"\n"+resSingularMismatch+"\n"+resSingularNotTypeKind,
+				"foo.Book", "tomes", "books", "projects/{project}/books/{book}",
+				"foo.Book", "tome", "{book}", "projects/{project}/books/{book}",
+				"foo.Book", "tome", "book"),
+			res: &annotations.ResourceDescriptor{
+				Type:     "foo.googleapis.com/Book",
+				Pattern:  []string{"projects/{project}/books/{book}"},
+				Singular: "tome",
+				Plural:   "tomes",
+			},
+		},
+		{
+			name: "mismatched singular variable",
+			want: fmt.Sprintf("\n"+resSingularMismatch, "foo.Book", "bookShelf", "{shelf_book}", "projects/{project}/shelfBooks/{shelf_book}"),
+			res: &annotations.ResourceDescriptor{
+				Type:     "foo.googleapis.com/BookShelf",
+				Pattern:  []string{"projects/{project}/shelfBooks/{shelf_book}"},
+				Singular: "bookShelf",
+			},
+		},
+		{
+			name: "sentinel pattern",
+			want: "",
+			res: &annotations.ResourceDescriptor{
+				Type:     "pubsub.googleapis.com/Topic",
+				Pattern:  []string{"projects/{project}/topics/{topic}", "_deleted-topic_"},
+				Singular: "topic",
+				Plural:   "topics",
+			},
+		},
+		{
+			name: "invalid plural format",
+			want: fmt.Sprintf("\n"+resPluralInvalid, "foo.Book", "Books"),
+			res: &annotations.ResourceDescriptor{
+				Type:     "foo.googleapis.com/Book",
+				Pattern:  []string{"book"},
+				Singular: "book",
+				Plural:   "Books",
+			},
+		},
+		{
+			name: "plural same as singular",
+			want: fmt.Sprintf("\n"+resPluralIsSingular, "foo.Book", "book"),
+			res: &annotations.ResourceDescriptor{
+				Type:     "foo.googleapis.com/Book",
+				Pattern:  []string{"book"},
+				Singular: "book",
+				Plural:   "book",
+			},
+		},
+		{
+			name: "valid future multi-pattern history",
+			want: "",
+			res: &annotations.ResourceDescriptor{
+				Type:    "foo.googleapis.com/Book",
+				Pattern: []string{"projects/{project}/books/{book}"},
+				History: annotations.ResourceDescriptor_FUTURE_MULTI_PATTERN,
+			},
+		},
+		{
+			name: "invalid future multi-pattern history",
+			want: fmt.Sprintf("\n"+resFutureMultiPattern, "foo.Book", 2),
+			res: &annotations.ResourceDescriptor{
+				Type:    "foo.googleapis.com/Book",
+				Pattern: []string{"projects/{project}/books/{book}", "folders/{folder}/books/{book}"},
+				History: annotations.ResourceDescriptor_FUTURE_MULTI_PATTERN,
+			},
+		},
+		{
+			name: "invalid originally single pattern history",
+			want: fmt.Sprintf("\n"+resOrigSinglePattern, "foo.Book", 1),
+			res: &annotations.ResourceDescriptor{
+				Type:    "foo.googleapis.com/Book",
+				Pattern: []string{"projects/{project}/books/{book}"},
+				History: annotations.ResourceDescriptor_ORIGINALLY_SINGLE_PATTERN,
+			},
+		},
+	} {
+		v.validateResourceDescriptor(tst.res, "foo.Book")
+
+		if actual := v.resp.GetError(); actual != tst.want {
+			t.Errorf("%s: got(%s) want(%s)", tst.name, actual, tst.want)
+		}
+
+		// reset resp.Error field between tests
+		v.resp.Error = nil
+	}
+}
+
+func TestValidateMessage_DeclarativeFriendly(t *testing.T) {
+	var v validator
+	resourceTypeKindRegexp = regexp.MustCompile("[A-Z][a-zA-Z0-9]+")
+
+	resOpts := messageOpts(t, annotations.E_Resource, &annotations.ResourceDescriptor{
+		Type:    "foo.googleapis.com/Book",
+		Pattern: []string{"books/{book}"},
+		Style:   []annotations.ResourceDescriptor_Style{annotations.ResourceDescriptor_DECLARATIVE_FRIENDLY},
+	})
+
+	book := builder.NewMessage("Book").SetOptions(resOpts).
+		AddField(builder.NewField("name", builder.FieldTypeString()))
+	create := builder.NewMessage("CreateBookRequest").
+		AddField(builder.NewField("book", builder.FieldTypeMessage(book))).
+		AddField(builder.NewField("validate_only", builder.FieldTypeBool()))
+	del := builder.NewMessage("DeleteBookRequest").
+		AddField(builder.NewField("name", builder.FieldTypeString()))
+
+	serv := builder.NewService("Library").
+		AddMethod(builder.NewMethod("CreateBook", builder.RpcTypeMessage(create, false), builder.RpcTypeMessage(book, false))).
+		AddMethod(builder.NewMethod("DeleteBook", builder.RpcTypeMessage(del, false), builder.RpcTypeMessage(book, false)))
+
+	f, err := builder.NewFile("library.proto").SetPackageName("library").
+		AddMessage(book).AddMessage(create).AddMessage(del).AddService(serv).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"library.proto": f}
+
+	want := fmt.Sprintf("\n"+resDeclMissingEtag+"\n"+resDeclMissingValidate,
+		"library.Book",
+		"library.Library.DeleteBook", "foo.googleapis.com/Book", "library.DeleteBookRequest")
+
+	v.validateMessage(f.FindMessage("library.Book"))
+
+	if actual := v.resp.GetError(); actual != want {
+		t.Errorf("got(%s) want(%s)", actual, want)
+	}
+}
+
+func TestValidateMessage_NameField(t *testing.T) {
+	var v validator
+	resourceTypeKindRegexp = regexp.MustCompile("[A-Z][a-zA-Z0-9]+")
+
+	resOpts := func(kind string) *descriptor.MessageOptions {
+		return resourceOpts(t, "foo.googleapis.com/"+kind, "books/{book}")
+	}
+
+	behavior := func(b ...annotations.FieldBehavior) *descriptor.FieldOptions {
+		return fieldOpts(t, annotations.E_FieldBehavior, b)
+	}
+
+	valid, err := builder.NewMessage("Valid").SetOptions(resOpts("Valid")).
+		AddField(builder.NewField("name", builder.FieldTypeString()).SetOptions(behavior(annotations.FieldBehavior_IDENTIFIER))).
+		Build()
+	if err != nil {
+		t.Error(err)
+	}
+
+	repeatedInt, err := builder.NewMessage("RepeatedInt").SetOptions(resOpts("RepeatedInt")).
+		AddField(builder.NewField("name", builder.FieldTypeInt64()).SetRepeated()).
+		Build()
+	if err != nil {
+		t.Error(err)
+	}
+
+	oneof, err := builder.NewMessage("Oneof").SetOptions(resOpts("Oneof")).
+		AddOneOf(builder.NewOneOf("id").AddChoice(builder.NewField("name", builder.FieldTypeString()))).
+		Build()
+	if err != nil {
+		t.Error(err)
+	}
+
+	conflict, err := builder.NewMessage("Conflict").SetOptions(resOpts("Conflict")).
+		AddField(builder.NewField("name", builder.FieldTypeString()).SetOptions(behavior(annotations.FieldBehavior_REQUIRED, annotations.FieldBehavior_OUTPUT_ONLY))).
+		Build()
+	if err != nil {
+		t.Error(err)
+	}
+
+	identOutput, err := builder.NewMessage("IdentOutput").SetOptions(resOpts("IdentOutput")).
+		AddField(builder.NewField("name", builder.FieldTypeString()).SetOptions(behavior(annotations.FieldBehavior_IDENTIFIER, annotations.FieldBehavior_OUTPUT_ONLY))).
+		Build()
+	if err != nil {
+		t.Error(err)
+	}
+
+	outputOnly, err := builder.NewMessage("OutputOnly").SetOptions(resOpts("OutputOnly")).
+		AddField(builder.NewField("name", builder.FieldTypeString()).SetOptions(behavior(annotations.FieldBehavior_OUTPUT_ONLY))).
+		Build()
+	if err != nil {
+		t.Error(err)
+	}
+
+	notIdent, err := builder.NewMessage("NotIdent").SetOptions(resOpts("NotIdent")).
+		AddField(builder.NewField("name", builder.FieldTypeString()).SetOptions(behavior(annotations.FieldBehavior_IMMUTABLE))).
+		Build()
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, tst := range []struct {
+		name, want string
+		warnings   []string
+		msg        *desc.MessageDescriptor
+	}{
+		{name: "valid name field", want: "", msg: valid},
+		{name: "repeated int64 name field", want: fmt.Sprintf("\n"+resNameFieldNotString+"\n"+resNameFieldRepeated, "RepeatedInt.name", "RepeatedInt.name"), msg: repeatedInt},
+		{name: "name field in oneof", want: fmt.Sprintf("\n"+resNameFieldInOneof, "Oneof.name", "id"), msg: oneof},
+		{name: "REQUIRED name field", want: fmt.Sprintf("\n"+resNameFieldConflict, "Conflict.name", annotations.FieldBehavior_REQUIRED), msg: conflict},
+		{name: "IDENTIFIER and OUTPUT_ONLY", want: fmt.Sprintf("\n"+resNameFieldIdentOutput, "IdentOutput.name"), msg: identOutput},
+		{name: "OUTPUT_ONLY name field", want: "", warnings: []string{fmt.Sprintf(resNameFieldNotIdent, "OutputOnly.name")}, msg: outputOnly},
+		{name: "missing IDENTIFIER", want: "", warnings: []string{fmt.Sprintf(resNameFieldNotIdent, "NotIdent.name")}, msg: notIdent},
+	} {
+		v.validateMessage(tst.msg)
+
+		if actual := v.resp.GetError(); actual != tst.want {
+			t.Errorf("%s: got(%s) want(%s)", tst.name, actual, tst.want)
+		}
+
+		if fmt.Sprint(v.warnings) != fmt.Sprint(tst.warnings) {
+			t.Errorf("%s warnings: got(%v) want(%v)", tst.name, v.warnings, tst.warnings)
+		}
+
+		// reset resp.Error field and warnings between tests
+		v.resp.Error = nil
+		v.warnings = nil
+	}
+}
+
+func TestValidateResourceUniqueness(t *testing.T) {
+	var v validator
+
+	defOpts := &descriptor.FileOptions{}
+	setExt(t, defOpts, annotations.E_ResourceDefinition, []*annotations.ResourceDescriptor{
+		{Type: "foo.googleapis.com/Shelf", Pattern: []string{"shelves/{shelf}"}},
+		{Type: "foo.googleapis.com/Author", Pattern: []string{"authors/{author}"}},
+	})
+
+	a, err := builder.NewFile("a.proto").SetPackageName("a").SetOptions(defOpts).
+		AddMessage(builder.NewMessage("Book").SetOptions(resourceOpts(t, "foo.googleapis.com/Book", "books/{book}"))).
+		AddMessage(builder.NewMessage("Shelf").SetOptions(resourceOpts(t, "foo.googleapis.com/Shelf", "shelves/{shelf}"))).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := builder.NewFile("b.proto").SetPackageName("b").SetOptions(defOpts).
+		AddMessage(builder.NewMessage("Book").SetOptions(resourceOpts(t, "foo.googleapis.com/Book", "books/{book}"))).
+		AddMessage(builder.NewMessage("Writer").SetOptions(resourceOpts(t, "foo.googleapis.com/Writer", "authors/{author}"))).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"a.proto": a, "b.proto": b}
+
+	want := fmt.Sprintf("\n"+resTypeConflict+"\n"+resPatternConflict,
+		"foo.googleapis.com/Book", `message "a.Book" in a.proto:1, message "b.Book" in b.proto:1`,
+		"authors/{author}", `"foo.googleapis.com/Author" (resource_definition in a.proto), "foo.googleapis.com/Writer" (message "b.Writer" in b.proto:1)`,
+	)
+
+	wantWarnings := []string{fmt.Sprintf(resTypeRedefined, "foo.googleapis.com/Shelf",
+		`resource_definition in a.proto, message "a.Shelf" in a.proto:1, resource_definition in b.proto`)}
+
+	v.validateResourceUniqueness(map[string]bool{"b.proto": true})
+
+	if actual := v.resp.GetError(); actual != want {
+		t.Errorf("got(%s) want(%s)", actual, want)
+	}
+
+	if fmt.Sprint(v.warnings) != fmt.Sprint(wantWarnings) {
+		t.Errorf("warnings: got(%v) want(%v)", v.warnings, wantWarnings)
+	}
+}
+
+func TestValidateMessage_ChildType(t *testing.T) {
+	var v validator
+
+	childRef := func(typ string) *descriptor.FieldOptions {
+		return fieldOpts(t, annotations.E_ResourceReference, &annotations.ResourceReference{ChildType: typ})
+	}
+
+	name := builder.NewField("name", builder.FieldTypeString())
+	f, err := builder.NewFile("library.proto").SetPackageName("library").
+		AddMessage(builder.NewMessage("Shelf").SetOptions(resourceOpts(t, "library.googleapis.com/Shelf", "shelves/{shelf}")).AddField(name)).
+		AddMessage(builder.NewMessage("Book").SetOptions(resourceOpts(t, "library.googleapis.com/Book", "shelves/{shelf}/books/{book}", "projects/{project}/books/{book}")).AddField(builder.NewField("name", builder.FieldTypeString()))).
+		AddMessage(builder.NewMessage("Page").SetOptions(resourceOpts(t, "library.googleapis.com/Page", "libraries/{library}/pages/{page}")).AddField(builder.NewField("name", builder.FieldTypeString()))).
+		AddMessage(builder.NewMessage("ListRequest").
+			AddField(builder.NewField("books_parent", builder.FieldTypeString()).SetOptions(childRef("library.googleapis.com/Book"))).
+			AddField(builder.NewField("shelves_parent", builder.FieldTypeString()).SetOptions(childRef("library.googleapis.com/Shelf"))).
+			AddField(builder.NewField("pages_parent", builder.FieldTypeString()).SetOptions(childRef("library.googleapis.com/Page"))).
+			AddField(builder.NewField("locations_parent", builder.FieldTypeString()).SetOptions(childRef("locations.googleapis.com/Location"))).
+			AddField(builder.NewField("projects_parent", builder.FieldTypeString()).SetOptions(childRef("cloudresourcemanager.googleapis.com/Project")))).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"library.proto": f}
+
+	want := fmt.Sprintf("\n"+resRefChildNoParent+"\n"+resRefChildNoKnown+"\n"+resRefChildNoParent,
+		"library.googleapis.com/Shelf", "library.ListRequest.shelves_parent",
+		"library.googleapis.com/Page", "library.ListRequest.pages_parent", "libraries/{library}",
+		"cloudresourcemanager.googleapis.com/Project", "library.ListRequest.projects_parent",
+	)
+
+	v.validateMessage(f.FindMessage("library.ListRequest"))
+
+	if actual := v.resp.GetError(); actual != want {
+		t.Errorf("got(%s) want(%s)", actual, want)
+	}
+}
+
+func TestValidateMessage_ResRefFieldType(t *testing.T) {
+	var v validator
+	resourceTypeKindRegexp = regexp.MustCompile("[A-Z][a-zA-Z0-9]+")
+
+	resOpts := resourceOpts(t, "library.googleapis.com/Book", "books/{book}")
+
+	ref := func() *descriptor.FieldOptions {
+		return fieldOpts(t, annotations.E_ResourceReference, &annotations.ResourceReference{Type: "library.googleapis.com/Book"})
+	}
+
+	f, err := builder.NewFile("library.proto").SetPackageName("library").
+		AddMessage(builder.NewMessage("Book").SetOptions(resOpts).
+			AddField(builder.NewField("name", builder.FieldTypeString()).SetOptions(ref())).
+			AddField(builder.NewField("related", builder.FieldTypeString()).SetRepeated().SetOptions(ref())).
+			AddField(builder.NewField("id", builder.FieldTypeInt64()).SetOptions(ref()))).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"library.proto": f}
+
+	v.validateMessage(f.FindMessage("library.Book"))
+
+	if want, actual := fmt.Sprintf("\n"+resRefNotString, "library.Book.id", "int64"), v.resp.GetError(); actual != want {
+		t.Errorf("got(%s) want(%s)", actual, want)
+	}
+
+	if want := []string{fmt.Sprintf(resRefOnNameField, "library.Book.name", "library.Book")}; fmt.Sprint(v.warnings) != fmt.Sprint(want) {
+		t.Errorf("warnings: got(%v) want(%v)", v.warnings, want)
+	}
+}
+
+func TestWellKnownCatalog(t *testing.T) {
+	for _, tst := range []struct{ typ, pat string }{
+		{"cloudresourcemanager.googleapis.com/Project", "projects/{project}"},
+		{"cloudresourcemanager.googleapis.com/Organization", "organizations/{organization}"},
+		{"cloudresourcemanager.googleapis.com/Folder", "folders/{folder}"},
+		{"cloudbilling.googleapis.com/BillingAccount", "billingAccounts/{billing_account}"},
+		{"cloudbilling.googleapis.com/BillingAccount", "billingAccounts/{billing_account_id}"},
+		{"locations.googleapis.com/Location", "projects/{project}/locations/{location}"},
+	} {
+		typ, pat := tst.typ, tst.pat
+		if res := wellKnown.resources[typ]; !containStr(res.GetPattern(), pat) {
+			t.Errorf("wellKnown.resources[%q] missing pattern %q", typ, pat)
+		}
+
+		if !wellKnown.patterns[pat] {
+			t.Errorf("wellKnown.patterns missing %q", pat)
+		}
+	}
+}
+
+func TestParseParameters_WellKnownResources(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "resources.yaml")
+	yamlData := `resources:
+- type: iam.example.com/ServiceAccount
+  pattern:
+  - projects/{project}/serviceAccounts/{service_account}
+`
+	if err := ioutil.WriteFile(yamlPath, []byte(yamlData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	textPath := filepath.Join(dir, "resources.textproto")
+	textData := `[google.api.resource_definition] {
+  type: "kms.example.com/KeyRing"
+  pattern: "projects/{project}/keyRings/{key_ring}"
+}
+`
+	if err := ioutil.WriteFile(textPath, []byte(textData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var v validator
+	if err := v.parseParameters("well-known-resources=" + yamlPath + ",well-known-resources=" + textPath); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, typ := range []string{"iam.example.com/ServiceAccount", "kms.example.com/KeyRing"} {
+		if !v.isWellKnownType(typ) {
+			t.Errorf("isWellKnownType(%q): got(false) want(true)", typ)
+		}
+	}
+
+	for _, pat := range []string{"projects/{project}/serviceAccounts/{service_account}", "projects/{project}/keyRings/{key_ring}"} {
+		if !v.isWellKnownPattern(pat) {
+			t.Errorf("isWellKnownPattern(%q): got(false) want(true)", pat)
+		}
+	}
+
+	for _, name := range []string{"service_account", "key_ring"} {
+		if !v.isWellKnownName(name) {
+			t.Errorf("isWellKnownName(%q): got(false) want(true)", name)
+		}
+	}
+
+	// the built-in catalog is still consulted
+	if !v.isWellKnownType("cloudresourcemanager.googleapis.com/Project") {
+		t.Error("isWellKnownType(cloudresourcemanager.googleapis.com/Project): got(false) want(true)")
+	}
+
+	// loaded resources do not leak into other validation runs
+	var other validator
+	if other.isWellKnownType("iam.example.com/ServiceAccount") || other.isWellKnownPattern("projects/{project}/keyRings/{key_ring}") {
+		t.Error("well-known resources loaded by one validator are visible to another")
+	}
+
+	// references to loaded types resolve
+	refOpts := fieldOpts(t, annotations.E_ResourceReference, &annotations.ResourceReference{Type: "iam.example.com/ServiceAccount"})
+	f, err := builder.NewFile("ref.proto").SetPackageName("foo").
+		AddMessage(builder.NewMessage("Request").
+			AddField(builder.NewField("service_account", builder.FieldTypeString()).SetOptions(refOpts))).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"ref.proto": f}
+	other.files = v.files
+
+	v.validateMessage(f.FindMessage("foo.Request"))
+	if actual := v.resp.GetError(); actual != "" {
+		t.Errorf("validateResRef(loaded type): got(%s) want()", actual)
+	}
+
+	other.validateMessage(f.FindMessage("foo.Request"))
+	want := fmt.Sprintf("\n"+resRefNotValidResource, "foo.Request.service_account", "iam.example.com/ServiceAccount")
+	if actual := other.resp.GetError(); actual != want {
+		t.Errorf("validateResRef(unknown type): got(%s) want(%s)", actual, want)
+	}
+
+	if err := v.parseParameters("well-known-resources=" + filepath.Join(dir, "dne.yaml")); err == nil {
+		t.Error("expected error reading missing well-known resources file")
+	}
+}
+
+func TestValidateMethod_LROSemantics(t *testing.T) {
+	var v validator
+
+	lroDesc, err := desc.LoadMessageDescriptorForMessage(&longrunning.Operation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	emptyDesc, err := desc.LoadMessageDescriptorForMessage(&empty.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serv := builder.NewService("Service")
+	fooFile := builder.NewFile("foo.proto").SetPackageName("foo.v1").
+		AddMessage(builder.NewMessage("Outer").AddNestedMessage(builder.NewMessage("Inner"))).
+		AddMessage(builder.NewMessage("Resp")).
+		AddMessage(builder.NewMessage("Meta")).
+		AddService(serv)
+
+	// files that foo.proto does not import, nor does it import empty.proto
+	var others []*desc.FileDescriptor
+	for _, fb := range []*builder.FileBuilder{
+		builder.NewFile("other.proto").SetPackageName("other").AddMessage(builder.NewMessage("Thing")),
+		builder.NewFile("common.proto").SetPackageName("foo").AddMessage(builder.NewMessage("Common")),
+		builder.NewFile("foo_shelf.proto").SetPackageName("foo.shelf").AddMessage(builder.NewMessage("Item")),
+		builder.NewFile("shelf.proto").SetPackageName("shelf").AddMessage(builder.NewMessage("Item")),
+		builder.NewFile("a.proto").SetPackageName("a").AddMessage(builder.NewMessage("Dup")),
+		builder.NewFile("b.proto").SetPackageName("b").AddMessage(builder.NewMessage("Dup")),
+	} {
+		o, err := fb.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		others = append(others, o)
+	}
+
+	lro := builder.RpcTypeImportedMessage(lroDesc, false)
+	for name, info := range map[string]*longrunning.OperationInfo{
+		"Valid":       {ResponseType: "Outer.Inner", MetadataType: "v1.Meta"},
+		"ValidEmpty":  {ResponseType: "google.protobuf.Empty", MetadataType: "Meta"},
+		"Operation":   {ResponseType: "google.longrunning.Operation", MetadataType: "Meta"},
+		"Unqualified": {ResponseType: "Empty", MetadataType: "Meta"},
+		"Same":        {ResponseType: "Resp", MetadataType: "foo.v1.Resp"},
+		"Qualified":   {ResponseType: "other.Thing", MetadataType: ".foo.Common"},
+		"WellKnown":   {ResponseType: "google.protobuf.Duration", MetadataType: "Meta"},
+		"NotImported": {ResponseType: "Common", MetadataType: "Meta"},
+		"Enclosing":   {ResponseType: "shelf.Item", MetadataType: "Meta"},
+		"OutOfScope":  {ResponseType: "Resp", MetadataType: "Thing"},
+		"Ambiguous":   {ResponseType: "Dup", MetadataType: "Meta"},
+	} {
+		serv.AddMethod(builder.NewMethod(name, lro, lro).SetOptions(methodOpts(t, longrunning.E_OperationInfo, info)))
+	}
+
+	f, err := fooFile.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{
+		"foo.proto":                   f,
+		lroDesc.GetFile().GetName():   lroDesc.GetFile(),
+		emptyDesc.GetFile().GetName(): emptyDesc.GetFile(),
+	}
+	for _, o := range others {
+		v.files[o.GetName()] = o
+	}
+	s := f.FindService("foo.v1.Service")
+
+	for _, tst := range []struct {
+		name, want string
+		warnings   []string
+	}{
+		{name: "Valid", want: ""},
+		{
+			name: "ValidEmpty",
+			want: "",
+			warnings: []string{fmt.Sprintf(lroTypeResolution, "response_type", "google.protobuf.Empty", "foo.v1.Service.ValidEmpty",
+				`resolves to "google.protobuf.Empty" in "google/protobuf/empty.proto", which is not imported by "foo.proto"`)},
+		},
+		{name: "Operation", want: fmt.Sprintf("\n"+lroResponseIsOperation, "foo.v1.Service.Operation")},
+		{name: "Unqualified", want: fmt.Sprintf("\n"+lroEmptyNotQualified, "foo.v1.Service.Unqualified", "response_type", "Empty")},
+		{name: "Same", want: fmt.Sprintf("\n"+lroMetadataIsResponse, "foo.v1.Service.Same", "foo.v1.Resp")},
+		{
+			name: "Qualified",
+			want: "",
+			warnings: []string{
+				fmt.Sprintf(lroTypeResolution, "response_type", "other.Thing", "foo.v1.Service.Qualified",
+					`resolves to "other.Thing" in "other.proto", which is not imported by "foo.proto"`),
+				fmt.Sprintf(lroTypeResolution, "metadata_type", ".foo.Common", "foo.v1.Service.Qualified",
+					`resolves to "foo.Common" in "common.proto", which is not imported by "foo.proto"`),
+			},
+		},
+		{
+			name: "WellKnown",
+			want: "",
+			warnings: []string{fmt.Sprintf(lroTypeResolution, "response_type", "google.protobuf.Duration", "foo.v1.Service.WellKnown",
+				`resolves to well-known type "google.protobuf.Duration", which is not imported by "foo.proto"`)},
+		},
+		{
+			name: "NotImported",
+			want: "",
+			warnings: []string{fmt.Sprintf(lroTypeResolution, "response_type", "Common", "foo.v1.Service.NotImported",
+				`resolves to "foo.Common" in "common.proto", which is not imported by "foo.proto"`)},
+		},
+		{
+			name: "Enclosing",
+			want: "",
+			warnings: []string{fmt.Sprintf(lroTypeResolution, "response_type", "shelf.Item", "foo.v1.Service.Enclosing",
+				`resolves to "foo.shelf.Item" in "foo_shelf.proto", which is not imported by "foo.proto"`)},
+		},
+		{
+			name: "OutOfScope",
+			want: fmt.Sprintf("\n"+lroTypeResolution, "metadata_type", "Thing", "foo.v1.Service.OutOfScope",
+				`resolves to "other.Thing" outside the scope of package "foo.v1", use the fully-qualified name`),
+		},
+		{
+			name: "Ambiguous",
+			want: fmt.Sprintf("\n"+lroTypeResolution, "response_type", "Dup", "foo.v1.Service.Ambiguous",
+				`is ambiguous, it could refer to any of "a.Dup", "b.Dup"`),
+		},
+	} {
+		v.validateMethod(s.FindMethodByName(tst.name))
+
+		if actual := v.resp.GetError(); actual != tst.want {
+			t.Errorf("%s: got(%s) want(%s)", tst.name, actual, tst.want)
+		}
+
+		if fmt.Sprint(v.warnings) != fmt.Sprint(tst.warnings) {
+			t.Errorf("%s warnings: got(%v) want(%v)", tst.name, v.warnings, tst.warnings)
+		}
+
+		// reset resp.Error field and warnings between tests
+		v.resp.Error = nil
+		v.warnings = nil
+	}
+}
+
+func TestValidateExtendedOperations(t *testing.T) {
+	var v validator
+
+	opField := func(m extendedops.OperationResponseMapping) *descriptor.FieldOptions {
+		return fieldOpts(t, extendedops.E_OperationField, &m)
+	}
+	service := func(s string) *descriptor.MethodOptions {
+		return methodOpts(t, extendedops.E_OperationService, proto.String(s))
+	}
+	polling := methodOpts(t, extendedops.E_OperationPollingMethod, proto.Bool(true))
+
+	op := builder.NewMessage("Operation").
+		AddField(builder.NewField("name", builder.FieldTypeString()).SetOptions(opField(extendedops.OperationResponseMapping_NAME))).
+		AddField(builder.NewField("status", builder.FieldTypeEnum(builder.NewEnum("Status").AddValue(builder.NewEnumValue("DONE")))).
+			SetOptions(opField(extendedops.OperationResponseMapping_STATUS))).
+		AddField(builder.NewField("http_error_status_code", builder.FieldTypeInt32()).SetOptions(opField(extendedops.OperationResponseMapping_ERROR_CODE))).
+		AddField(builder.NewField("http_error_message", builder.FieldTypeString()).SetOptions(opField(extendedops.OperationResponseMapping_ERROR_MESSAGE)))
+	badOp := builder.NewMessage("BadOperation").
+		AddField(builder.NewField("name", builder.FieldTypeInt64()).SetOptions(opField(extendedops.OperationResponseMapping_NAME))).
+		AddField(builder.NewField("status", builder.FieldTypeBool()).SetOptions(opField(extendedops.OperationResponseMapping_STATUS))).
+		AddField(builder.NewField("done", builder.FieldTypeBool()).SetOptions(opField(extendedops.OperationResponseMapping_STATUS))).
+		AddField(builder.NewField("kind", builder.FieldTypeString()).SetOptions(opField(extendedops.OperationResponseMapping_UNDEFINED)))
+	plain := builder.NewMessage("Plain")
+
+	getReq := builder.NewMessage("GetZoneOperationRequest").
+		AddField(builder.NewField("operation", builder.FieldTypeString()).SetOptions(fieldOpts(t, extendedops.E_OperationResponseField, proto.String("name")))).
+		AddField(builder.NewField("project", builder.FieldTypeString())).
+		AddField(builder.NewField("zone", builder.FieldTypeString()))
+	badGetReq := builder.NewMessage("BadGetRequest").
+		AddField(builder.NewField("operation", builder.FieldTypeString()).SetOptions(fieldOpts(t, extendedops.E_OperationResponseField, proto.String("id"))))
+	insertReq := builder.NewMessage("InsertRequest").
+		AddField(builder.NewField("project", builder.FieldTypeString()).SetOptions(fieldOpts(t, extendedops.E_OperationRequestField, proto.String("project")))).
+		AddField(builder.NewField("zone", builder.FieldTypeString()).SetOptions(fieldOpts(t, extendedops.E_OperationRequestField, proto.String("zone"))))
+	badInsertReq := builder.NewMessage("BadInsertRequest").
+		AddField(builder.NewField("region", builder.FieldTypeString()).SetOptions(fieldOpts(t, extendedops.E_OperationRequestField, proto.String("region"))))
+
+	rpc := builder.RpcTypeMessage
+	zoneOps := builder.NewService("ZoneOperations").
+		AddMethod(builder.NewMethod("Get", rpc(getReq, false), rpc(op, false)).SetOptions(polling))
+	multiOps := builder.NewService("MultiOperations").
+		AddMethod(builder.NewMethod("Get", rpc(getReq, false), rpc(op, false)).SetOptions(polling)).
+		AddMethod(builder.NewMethod("Wait", rpc(getReq, false), rpc(op, false)).SetOptions(polling))
+	badOps := builder.NewService("BadOperations").
+		AddMethod(builder.NewMethod("Plain", rpc(plain, false), rpc(plain, false)).SetOptions(polling)).
+		AddMethod(builder.NewMethod("Get", rpc(badGetReq, false), rpc(op, false)).SetOptions(polling))
+	instances := builder.NewService("Instances").
+		AddMethod(builder.NewMethod("Insert", rpc(insertReq, false), rpc(op, false)).SetOptions(service("ZoneOperations"))).
+		AddMethod(builder.NewMethod("FullyQualified", rpc(insertReq, false), rpc(op, false)).SetOptions(service(".compute.v1.ZoneOperations"))).
+		AddMethod(builder.NewMethod("BadMapping", rpc(badInsertReq, false), rpc(op, false)).SetOptions(service("ZoneOperations"))).
+		AddMethod(builder.NewMethod("Missing", rpc(insertReq, false), rpc(op, false)).SetOptions(service("Missing"))).
+		AddMethod(builder.NewMethod("NoPolling", rpc(insertReq, false), rpc(op, false)).SetOptions(service("Instances"))).
+		AddMethod(builder.NewMethod("WrongResponse", rpc(insertReq, false), rpc(badOp, false)).SetOptions(service("ZoneOperations"))).
+		AddMethod(builder.NewMethod("NotCustom", rpc(insertReq, false), rpc(plain, false)).SetOptions(service("ZoneOperations")))
+
+	f, err := builder.NewFile("compute.proto").SetPackageName("compute.v1").
+		AddMessage(op).AddMessage(badOp).AddMessage(plain).
+		AddMessage(getReq).AddMessage(badGetReq).AddMessage(insertReq).AddMessage(badInsertReq).
+		AddService(zoneOps).AddService(multiOps).AddService(badOps).AddService(instances).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"compute.proto": f}
+	inst := f.FindService("compute.v1.Instances")
+
+	for _, tst := range []struct {
+		name, want string
+		validate   func()
+	}{
+		{
+			name:     "valid operation",
+			validate: func() { v.validateMessage(f.FindMessage("compute.v1.Operation")) },
+		},
+		{
+			name: "invalid operation",
+			want: fmt.Sprintf("\n"+extOpFieldUndefined+"\n"+extOpFieldType+"\n"+extOpFieldDuplicate+"\n"+extOpFieldMissing+"\n"+extOpFieldMissing,
+				"compute.v1.BadOperation.kind",
+				"compute.v1.BadOperation.name", extendedops.OperationResponseMapping_NAME, "string", "int64",
+				"compute.v1.BadOperation", extendedops.OperationResponseMapping_STATUS, `"status", "done"`,
+				"compute.v1.BadOperation", extendedops.OperationResponseMapping_ERROR_CODE,
+				"compute.v1.BadOperation", extendedops.OperationResponseMapping_ERROR_MESSAGE,
+			),
+			validate: func() { v.validateMessage(f.FindMessage("compute.v1.BadOperation")) },
+		},
+		{
+			name:     "valid polling service",
+			want:     fmt.Sprintf("\n"+missingDefaultHost, "compute.v1.ZoneOperations"),
+			validate: func() { v.validateService(f.FindService("compute.v1.ZoneOperations")) },
+		},
+		{
+			name: "multiple polling methods",
+			want: fmt.Sprintf("\n"+missingDefaultHost+"\n"+extOpMultiplePolling,
+				"compute.v1.MultiOperations",
+				"compute.v1.MultiOperations", `"Get", "Wait"`,
+			),
+			validate: func() { v.validateService(f.FindService("compute.v1.MultiOperations")) },
+		},
+		{
+			name: "invalid polling methods",
+			want: fmt.Sprintf("\n"+extOpPollingNotCustom+"\n"+extOpPollingNoMapping+"\n"+extOpResponseFieldDNE,
+				"compute.v1.BadOperations.Plain", "compute.v1.Plain",
+				"compute.v1.BadOperations.Plain", "compute.v1.Plain",
+				"compute.v1.BadGetRequest.operation", "id", "compute.v1.Operation",
+			),
+			validate: func() {
+				v.validateMethod(f.FindService("compute.v1.BadOperations").FindMethodByName("Plain"))
+				v.validateMethod(f.FindService("compute.v1.BadOperations").FindMethodByName("Get"))
+			},
+		},
+		{
+			name:     "valid operation_service",
+			validate: func() { v.validateMethod(inst.FindMethodByName("Insert")) },
+		},
+		{
+			name:     "fully-qualified operation_service",
+			validate: func() { v.validateMethod(inst.FindMethodByName("FullyQualified")) },
+		},
+		{
+			name: "unresolvable operation_request_field",
+			want: fmt.Sprintf("\n"+extOpRequestFieldDNE,
+				"compute.v1.BadInsertRequest.region", "region", "compute.v1.GetZoneOperationRequest"),
+			validate: func() { v.validateMethod(inst.FindMethodByName("BadMapping")) },
+		},
+		{
+			name:     "missing operation_service",
+			want:     fmt.Sprintf("\n"+extOpServiceDNE, "compute.v1.Instances.Missing", "Missing"),
+			validate: func() { v.validateMethod(inst.FindMethodByName("Missing")) },
+		},
+		{
+			name:     "no polling method",
+			want:     fmt.Sprintf("\n"+extOpNoPollingMethod, "compute.v1.Instances", "compute.v1.Instances.NoPolling"),
+			validate: func() { v.validateMethod(inst.FindMethodByName("NoPolling")) },
+		},
+		{
+			name: "polling response mismatch",
+			want: fmt.Sprintf("\n"+extOpPollingResponse,
+				"compute.v1.ZoneOperations.Get", "compute.v1.BadOperation", "compute.v1.Instances.WrongResponse", "compute.v1.Operation"),
+			validate: func() { v.validateMethod(inst.FindMethodByName("WrongResponse")) },
+		},
+		{
+			name: "not a custom operation",
+			want: fmt.Sprintf("\n"+extOpNotCustomOperation+"\n"+extOpPollingResponse,
+				"compute.v1.Instances.NotCustom", "compute.v1.Plain",
+				"compute.v1.ZoneOperations.Get", "compute.v1.Plain", "compute.v1.Instances.NotCustom", "compute.v1.Operation"),
+			validate: func() { v.validateMethod(inst.FindMethodByName("NotCustom")) },
+		},
+	} {
+		tst.validate()
+
+		if actual := v.resp.GetError(); actual != tst.want {
+			t.Errorf("%s: got(%s) want(%s)", tst.name, actual, tst.want)
+		}
+
+		// reset resp.Error field between tests
+		v.resp.Error = nil
+	}
+}
+
+func TestValidateMethod_Pagination(t *testing.T) {
+	var v validator
+
+	book := builder.NewMessage("Book")
+	req := func(name string, size *builder.FieldType) *builder.MessageBuilder {
+		return builder.NewMessage(name).
+			AddField(builder.NewField("page_size", size)).
+			AddField(builder.NewField("page_token", builder.FieldTypeString()))
+	}
+	listReq := req("ListBooksRequest", builder.FieldTypeInt32())
+	listResp := builder.NewMessage("ListBooksResponse").
+		AddField(builder.NewField("books", builder.FieldTypeMessage(book)).SetRepeated()).
+		AddField(builder.NewField("next_page_token", builder.FieldTypeString())).
+		AddField(builder.NewField("unreachable", builder.FieldTypeString()).SetRepeated())
+	int64Req := req("Int64Request", builder.FieldTypeInt64())
+	noNextResp := builder.NewMessage("NoNextResponse").
+		AddField(builder.NewField("books", builder.FieldTypeMessage(book)).SetRepeated())
+	multiResp := builder.NewMessage("MultiResponse").
+		AddField(builder.NewField("books", builder.FieldTypeMessage(book)).SetRepeated()).
+		AddField(builder.NewField("authors", builder.FieldTypeString()).SetRepeated()).
+		AddField(builder.NewField("next_page_token", builder.FieldTypeString()))
+	mapResp := builder.NewMessage("MapResponse").
+		AddField(builder.NewMapField("books", builder.FieldTypeString(), builder.FieldTypeMessage(book))).
+		AddField(builder.NewField("next_page_token", builder.FieldTypeString()))
+	emptyResp := builder.NewMessage("EmptyResponse").
+		AddField(builder.NewField("next_page_token", builder.FieldTypeString()))
+	tokenOnlyReq := builder.NewMessage("TokenOnlyRequest").
+		AddField(builder.NewField("page_token", builder.FieldTypeString()))
+
+	uint32Wrapper, err := desc.LoadMessageDescriptorForMessage(&wrapperspb.UInt32Value{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	maxReq := func(name string, size *builder.FieldType) *builder.MessageBuilder {
+		return builder.NewMessage(name).
+			AddField(builder.NewField("max_results", size)).
+			AddField(builder.NewField("page_token", builder.FieldTypeString()))
+	}
+	maxUint32Req := maxReq("MaxUint32Request", builder.FieldTypeUInt32())
+	maxWrapperReq := maxReq("MaxWrapperRequest", builder.FieldTypeImportedMessage(uint32Wrapper))
+	maxInt64Req := maxReq("MaxInt64Request", builder.FieldTypeInt64())
+
+	rpc := builder.RpcTypeMessage
+	serv := builder.NewService("Library").
+		AddMethod(builder.NewMethod("ListBooks", rpc(listReq, false), rpc(listResp, false))).
+		AddMethod(builder.NewMethod("GetBook", rpc(book, false), rpc(book, false))).
+		AddMethod(builder.NewMethod("StreamBooks", rpc(int64Req, false), rpc(noNextResp, true))).
+		AddMethod(builder.NewMethod("Int64", rpc(int64Req, false), rpc(listResp, false))).
+		AddMethod(builder.NewMethod("NoNext", rpc(listReq, false), rpc(noNextResp, false))).
+		AddMethod(builder.NewMethod("Multi", rpc(listReq, false), rpc(multiResp, false))).
+		AddMethod(builder.NewMethod("Map", rpc(listReq, false), rpc(mapResp, false))).
+		AddMethod(builder.NewMethod("Empty", rpc(tokenOnlyReq, false), rpc(emptyResp, false))).
+		AddMethod(builder.NewMethod("MaxUint32", rpc(maxUint32Req, false), rpc(listResp, false))).
+		AddMethod(builder.NewMethod("MaxWrapper", rpc(maxWrapperReq, false), rpc(listResp, false))).
+		AddMethod(builder.NewMethod("MaxInt64", rpc(maxInt64Req, false), rpc(listResp, false)))
+
+	f, err := builder.NewFile("library.proto").SetPackageName("library").
+		AddMessage(book).AddMessage(listReq).AddMessage(listResp).AddMessage(int64Req).
+		AddMessage(noNextResp).AddMessage(multiResp).AddMessage(mapResp).AddMessage(emptyResp).
+		AddMessage(tokenOnlyReq).AddMessage(maxUint32Req).AddMessage(maxWrapperReq).
+		AddMessage(maxInt64Req).AddService(serv).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := f.FindService("library.Library")
+
+	for _, tst := range []struct {
+		name, want string
+	}{
+		{name: "ListBooks", want: ""},
+		{name: "GetBook", want: ""},
+		{
+			name: "StreamBooks",
+			want: fmt.Sprintf("\n"+streamPaginated, "library.Library.StreamBooks"),
+		},
+		{
+			name: "Int64",
+			want: fmt.Sprintf("\n"+pageFieldType, "library.Library.Int64", "library.Int64Request.page_size", "int32", "int64"),
+		},
+		{
+			name: "NoNext",
+			want: fmt.Sprintf("\n"+pageMissingField, "library.Library.NoNext", "library.NoNextResponse", "next_page_token"),
+		},
+		{
+			name: "Multi",
+			want: fmt.Sprintf("\n"+pageMultipleResults, "library.Library.Multi", "library.MultiResponse", `"books", "authors"`),
+		},
+		{
+			name: "Map",
+			want: fmt.Sprintf("\n"+pageMapResults, "library.Library.Map", "library.MapResponse", "books"),
+		},
+		{
+			name: "Empty",
+			want: fmt.Sprintf("\n"+pageMissingField+"\n"+pageNoResults,
+				"library.Library.Empty", "library.TokenOnlyRequest", "page_size",
+				"library.Library.Empty", "library.EmptyResponse",
+			),
+		},
+		{name: "MaxUint32", want: ""},
+		{name: "MaxWrapper", want: ""},
+		{
+			name: "MaxInt64",
+			want: fmt.Sprintf("\n"+pageFieldType, "library.Library.MaxInt64", "library.MaxInt64Request.max_results",
+				"int32, uint32 or a wrapper of either", "int64"),
+		},
+	} {
+		v.validateMethod(s.FindMethodByName(tst.name))
+
+		if actual := v.resp.GetError(); actual != tst.want {
+			t.Errorf("%s: got(%s) want(%s)", tst.name, actual, tst.want)
+		}
+
+		// reset resp.Error field between tests
+		v.resp.Error = nil
+	}
+}
+
+func TestValidateMethod_Streaming(t *testing.T) {
+	var v validator
+
+	msg := builder.NewMessage("Msg").AddField(builder.NewField("a", builder.FieldTypeString()))
+	pageReq := builder.NewMessage("PageRequest").
+		AddField(builder.NewField("page_size", builder.FieldTypeInt32())).
+		AddField(builder.NewField("page_token", builder.FieldTypeString()))
+
+	lroDesc, err := desc.LoadMessageDescriptorForMessage(&longrunning.Operation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := methodOpts(t, annotations.E_MethodSignature, []string{"a"})
+	setExt(t, opts, annotations.E_Http, &annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: "/v1/msgs"}, Body: "*"})
+	lroOpts := methodOpts(t, longrunning.E_OperationInfo, &longrunning.OperationInfo{ResponseType: "Msg", MetadataType: "PageRequest"})
+
+	rpc := builder.RpcTypeMessage
+	lro := builder.RpcTypeImportedMessage(lroDesc, true)
+	serv := builder.NewService("Streamer").
+		AddMethod(builder.NewMethod("Server", rpc(msg, false), rpc(msg, true)).SetOptions(opts)).
+		AddMethod(builder.NewMethod("Client", rpc(msg, true), rpc(msg, false)).SetOptions(opts)).
+		AddMethod(builder.NewMethod("Bidi", rpc(msg, true), rpc(msg, true)).SetOptions(opts)).
+		AddMethod(builder.NewMethod("PlainBidi", rpc(msg, true), rpc(msg, true))).
+		AddMethod(builder.NewMethod("Operation", rpc(msg, false), lro).SetOptions(lroOpts)).
+		AddMethod(builder.NewMethod("Paged", rpc(pageReq, false), rpc(msg, true)))
+
+	f, err := builder.NewFile("stream.proto").SetPackageName("stream").
+		AddMessage(msg).AddMessage(pageReq).AddService(serv).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"stream.proto": f}
+	s := f.FindService("stream.Streamer")
+
+	for _, tst := range []struct {
+		name, want string
+	}{
+		{name: "Server", want: ""},
+		{name: "PlainBidi", want: ""},
+		{
+			name: "Client",
+			want: fmt.Sprintf("\n"+streamMethodSignature+"\n"+streamHTTP,
+				"client-streaming", "stream.Streamer.Client",
+				"client-streaming", "stream.Streamer.Client",
+			),
+		},
+		{
+			name: "Bidi",
+			want: fmt.Sprintf("\n"+streamMethodSignature+"\n"+streamHTTP,
+				"bidi-streaming", "stream.Streamer.Bidi",
+				"bidi-streaming", "stream.Streamer.Bidi",
+			),
+		},
+		{
+			name: "Operation",
+			want: fmt.Sprintf("\n"+streamOperationInfo, "server-streaming", "stream.Streamer.Operation"),
+		},
+		{
+			name: "Paged",
+			want: fmt.Sprintf("\n"+streamPaginated, "stream.Streamer.Paged"),
+		},
+	} {
+		v.validateMethod(s.FindMethodByName(tst.name))
+
+		if actual := v.resp.GetError(); actual != tst.want {
+			t.Errorf("%s: got(%s) want(%s)", tst.name, actual, tst.want)
+		}
+
+		// reset resp.Error field between tests
+		v.resp.Error = nil
+	}
+}
+
+func TestValidateServiceConfig(t *testing.T) {
+	var v validator
+
+	servOpts := serviceOpts(t, annotations.E_DefaultHost, proto.String("library.googleapis.com"))
+	otherOpts := serviceOpts(t, annotations.E_DefaultHost, proto.String("other.googleapis.com:443"))
+
+	book := builder.NewMessage("Book").AddField(builder.NewField("name", builder.FieldTypeString()))
+	rpc := builder.RpcTypeMessage
+	f, err := builder.NewFile("library.proto").SetPackageName("library.v1").
+		AddMessage(book).
+		AddService(builder.NewService("Library").SetOptions(servOpts).
+			AddMethod(builder.NewMethod("GetBook", rpc(book, false), rpc(book, false)))).
+		AddService(builder.NewService("Other").SetOptions(otherOpts)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"library.proto": f}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "library_v1.yaml")
+	data := `type: google.api.Service
+config_version: 3
+name: library.googleapis.com
+title: Library API
+
+apis:
+- name: library.v1.Library
+- name: library.v1.Other
+- name: library.v1.Missing
+
+http:
+  rules:
+  - selector: library.v1.Library.GetBook
+    get: '/v1/{name=books/*}'
+  - selector: library.v1.Book
+    get: '/v1/books'
+
+documentation:
+  rules:
+  - selector: library.v1.Library.*
+    description: Manages books.
+  - selector: library.v1.Book.name
+    description: The name of the book.
+  - selector: library.v1.Shelf.*
+    description: Not a thing.
+`
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.parseParameters("service-yaml=" + path); err != nil {
+		t.Fatal(err)
+	}
+	if got := v.serviceConfig.GetName(); got != "library.googleapis.com" {
+		t.Errorf("service config name: got(%s) want(library.googleapis.com)", got)
+	}
+
+	v.validateServiceConfig()
+
+	want := fmt.Sprintf("\n"+svcNameMismatch+"\n"+svcAPIDNE+"\n"+svcHTTPSelectorDNE+"\n"+svcDocSelectorDNE,
+		"library.googleapis.com", "other.googleapis.com:443", "library.v1.Other",
+		"library.v1.Missing",
+		"library.v1.Book",
+		"library.v1.Shelf.*",
+	)
+	if actual := v.resp.GetError(); actual != want {
+		t.Errorf("validateServiceConfig: got(%s) want(%s)", actual, want)
+	}
+
+	if err := v.parseParameters("service-yaml=" + filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("service-yaml: expected error for missing file")
+	}
+}
+
+func TestParseHTTPPath(t *testing.T) {
+	for _, tst := range []struct {
+		path string
+		vars []string
+		err  string
+	}{
+		{path: "/v1/{name=shelves/*/books/*}", vars: []string{"name"}},
+		{path: "/v1/{parent=shelves/*}/books", vars: []string{"parent"}},
+		{path: "/v1/{book.name=shelves/*/books/*}:publish", vars: []string{"book.name"}},
+		{path: "/v1/{name=operations/**}", vars: []string{"name"}},
+		{path: "/v1/shelves/{shelf}/books/{book}", vars: []string{"shelf", "book"}},
+		{path: "/v1/books:search"},
+		{path: "v1/books", err: "position 0: path must begin with '/'"},
+		{path: "/v1//books", err: "position 4: empty segment"},
+		{path: "/v1/books/", err: "position 9: trailing '/' is not allowed"},
+		{path: "/v1/{name=shelves/*", err: "position 4: unbalanced '{'"},
+		{path: "/v1/name}", err: "position 8: unbalanced '}'"},
+		{path: "/v1/x{name}", err: "position 5: variable must be an entire segment"},
+		{path: "/v1/{name}x", err: "position 10: variable must be an entire segment"},
+		{path: "/v1/{name=shelves//books}", err: `position 10: variable "name=shelves//books" has an empty segment`},
+		{path: "/v1/{na-me}", err: `position 5: invalid field path "na-me"`},
+		{path: "/v1/books:", err: `position 9: invalid custom verb ""`},
+	} {
+		gotVars, gotErr := parseHTTPPath(tst.path)
+
+		if tst.err != "" {
+			if gotErr == nil || gotErr.Error() != tst.err {
+				t.Errorf("parseHTTPPath(%q): got err(%v) want(%s)", tst.path, gotErr, tst.err)
+			}
+			continue
+		}
+
+		if gotErr != nil {
+			t.Errorf("parseHTTPPath(%q): unexpected error %v", tst.path, gotErr)
+		} else if fmt.Sprint(gotVars) != fmt.Sprint(tst.vars) {
+			t.Errorf("parseHTTPPath(%q): got(%v) want(%v)", tst.path, gotVars, tst.vars)
+		}
+	}
+}
+
+func TestValidateHTTPRule(t *testing.T) {
+	var v validator
+
+	httpOpts := func(rule *annotations.HttpRule) *descriptor.MethodOptions {
+		return methodOpts(t, annotations.E_Http, rule)
+	}
+
+	book := builder.NewMessage("Book").
+		AddField(builder.NewField("name", builder.FieldTypeString())).
+		AddField(builder.NewField("tags", builder.FieldTypeString()).SetRepeated())
+	req := builder.NewMessage("UpdateBookRequest").
+		AddField(builder.NewField("book", builder.FieldTypeMessage(book))).
+		AddField(builder.NewField("name", builder.FieldTypeString()))
+
+	rpc := builder.RpcTypeMessage
+	serv := builder.NewService("Library").
+		AddMethod(builder.NewMethod("Valid", rpc(req, false), rpc(book, false)).SetOptions(httpOpts(&annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Patch{Patch: "/v1/{book.name=shelves/*/books/*}"},
+			Body:    "book",
+			AdditionalBindings: []*annotations.HttpRule{{
+				Pattern: &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Kind: "HEAD", Path: "/v1/{name=books/*}"}},
+			}},
+		}))).
+		AddMethod(builder.NewMethod("BadFields", rpc(req, false), rpc(book, false)).SetOptions(httpOpts(&annotations.HttpRule{
+			Pattern:      &annotations.HttpRule_Get{Get: "/v1/{book}/{book.tags}/{shelf}/{name}/{name}"},
+			Body:         "*",
+			ResponseBody: "title",
+		}))).
+		AddMethod(builder.NewMethod("BadBody", rpc(req, false), rpc(book, false)).SetOptions(httpOpts(&annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Post{Post: "/v1/books//x"},
+			Body:    "shelf",
+			AdditionalBindings: []*annotations.HttpRule{{
+				Pattern:            &annotations.HttpRule_Post{Post: "/v1/books"},
+				AdditionalBindings: []*annotations.HttpRule{{Pattern: &annotations.HttpRule_Post{Post: "/v2/books"}}},
+			}},
+		}))).
+		AddMethod(builder.NewMethod("NoPath", rpc(req, false), rpc(book, false)).SetOptions(httpOpts(&annotations.HttpRule{})))
+
+	f, err := builder.NewFile("library.proto").SetPackageName("library").
+		AddMessage(book).AddMessage(req).AddService(serv).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"library.proto": f}
+	s := f.FindService("library.Library")
+
+	loc := func(m string) string {
+		return fmt.Sprintf("rpc %q google.api.http", "library.Library."+m)
+	}
+
+	for _, tst := range []struct {
+		name, want string
+	}{
+		{name: "Valid", want: ""},
+		{
+			name: "BadFields",
+			want: fmt.Sprintf("\n"+httpFieldNotPrimitive+"\n"+httpFieldRepeated+"\n"+httpFieldDNE+"\n"+httpDuplicateVar+"\n"+httpBodyNotAllowed+"\n"+httpResponseBodyDNE,
+				loc("BadFields"), "book", "library.Book",
+				loc("BadFields"), "book.tags",
+				loc("BadFields"), "shelf", "library.UpdateBookRequest",
+				loc("BadFields"), "/v1/{book}/{book.tags}/{shelf}/{name}/{name}", "name",
+				loc("BadFields"), "GET",
+				loc("BadFields"), "title", "library.Book",
+			),
+		},
+		{
+			name: "BadBody",
+			want: fmt.Sprintf("\n"+httpInvalidPath+"\n"+httpBodyDNE+"\n"+httpNestedBindings,
+				loc("BadBody"), "/v1/books//x", "position 10: empty segment",
+				loc("BadBody"), "shelf", "library.UpdateBookRequest",
+				loc("BadBody"),
+			),
+		},
+		{name: "NoPath", want: fmt.Sprintf("\n"+httpMissingPath, loc("NoPath"))},
+	} {
+		v.validateMethod(s.FindMethodByName(tst.name))
+
+		if actual := v.resp.GetError(); actual != tst.want {
+			t.Errorf("%s: got(%s) want(%s)", tst.name, actual, tst.want)
+		}
+
+		// reset resp.Error field between tests
+		v.resp.Error = nil
+	}
+
+	// service config overrides, including mixins
+	v.serviceConfig = &serviceconfig.Service{
+		Name: "library.googleapis.com",
+		Http: &annotations.Http{
+			Rules: []*annotations.HttpRule{
+				{Selector: "library.Library.Valid", Pattern: &annotations.HttpRule_Put{Put: "/v1/{book.title}"}},
+				{Selector: "google.longrunning.Operations.GetOperation", Pattern: &annotations.HttpRule_Get{Get: "/v1/{name=operations/**}"}},
+				{Selector: "google.cloud.location.Locations.GetLocation", Pattern: &annotations.HttpRule_Get{Get: "/v1/{location=projects/*/locations/*}"}},
+			},
+		},
+	}
+	v.validateServiceConfig()
+
+	yamlLoc := func(sel string) string {
+		return fmt.Sprintf("service config http.rules selector %q", sel)
+	}
+	want := fmt.Sprintf("\n"+httpFieldDNE+"\n"+httpFieldDNE+"\n"+svcMixinNotListed+"\n"+svcMixinNotListed,
+		yamlLoc("library.Library.Valid"), "book.title", "library.UpdateBookRequest",
+		yamlLoc("google.cloud.location.Locations.GetLocation"), "location", "google.cloud.location.GetLocationRequest",
+		"google.longrunning.Operations.GetOperation", "google.longrunning.Operations",
+		"google.cloud.location.Locations.GetLocation", "google.cloud.location.Locations",
+	)
+	if actual := v.resp.GetError(); actual != want {
+		t.Errorf("service config http rules: got(%s) want(%s)", actual, want)
+	}
+}
+
+func TestValidateMixins(t *testing.T) {
+	var v validator
+
+	hostOpts := serviceOpts(t, annotations.E_DefaultHost, proto.String("library.googleapis.com"))
+	httpOpts := methodOpts(t, annotations.E_Http, &annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: "/v1/books"}, Body: "*"})
+	lroOpts := methodOpts(t, longrunning.E_OperationInfo, &longrunning.OperationInfo{ResponseType: "Book", MetadataType: "google.protobuf.Empty"})
+
+	lroDesc, err := desc.LoadMessageDescriptorForMessage(&longrunning.Operation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book := builder.NewMessage("Book")
+	rpc := builder.RpcTypeMessage
+	f, err := builder.NewFile("library.proto").SetPackageName("library").
+		AddMessage(book).
+		AddService(builder.NewService("Library").SetOptions(hostOpts).
+			AddMethod(builder.NewMethod("CreateBook", rpc(book, false), rpc(book, false)).SetOptions(httpOpts)).
+			AddMethod(builder.NewMethod("ImportBooks", rpc(book, false), builder.RpcTypeImportedMessage(lroDesc, false)).SetOptions(lroOpts))).
+		AddService(builder.NewService("Plain").SetOptions(hostOpts).
+			AddMethod(builder.NewMethod("GetBook", rpc(book, false), rpc(book, false)))).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"library.proto": f}
+
+	get := func(sel, path string) *annotations.HttpRule {
+		return &annotations.HttpRule{Selector: sel, Pattern: &annotations.HttpRule_Get{Get: path}}
+	}
+	post := func(sel, path string) *annotations.HttpRule {
+		return &annotations.HttpRule{Selector: sel, Pattern: &annotations.HttpRule_Post{Post: path}, Body: "*"}
+	}
+
+	for _, tst := range []struct {
+		name, want string
+		apis       []string
+		rules      []*annotations.HttpRule
+	}{
+		{
+			name: "missing Operations mixin",
+			apis: []string{"library.Library", "google.iam.v1.IAMPolicy"},
+			rules: []*annotations.HttpRule{
+				post("google.iam.v1.IAMPolicy.GetIamPolicy", "/v1/{resource=books/*}:getIamPolicy"),
+				post("google.iam.v1.IAMPolicy.SetIamPolicy", "/v1/{resource=books/*}:setIamPolicy"),
+				post("google.iam.v1.IAMPolicy.TestIamPermissions", "/v1/{resource=books/*}:testIamPermissions"),
+			},
+			want: fmt.Sprintf("\n"+svcMissingOperationsMixin, "library.Library"),
+		},
+		{
+			name: "missing mixin http rules",
+			apis: []string{"library.Library", "google.longrunning.Operations", "google.cloud.location.Locations"},
+			rules: []*annotations.HttpRule{
+				get("google.longrunning.Operations.ListOperations", "/v1/{name=operations}"),
+				get("google.longrunning.Operations.GetOperation", "/v1/{name=operations/**}"),
+				{Selector: "google.longrunning.Operations.DeleteOperation", Pattern: &annotations.HttpRule_Delete{Delete: "/v1/{name=operations/**}"}},
+				post("google.longrunning.Operations.CancelOperation", "/v1/{name=operations/**}:cancel"),
+				get("google.cloud.location.Locations.GetLocation", "/v1/{name=projects/*/locations/*}"),
+			},
+			want: fmt.Sprintf("\n"+svcMixinMissingHTTP, "google.cloud.location.Locations.ListLocations"),
+		},
+		{
+			name: "no REST",
+			apis: []string{"library.Plain", "google.cloud.location.Locations"},
+			want: "",
+		},
+	} {
+		v.serviceConfig = &serviceconfig.Service{
+			Name: "library.googleapis.com",
+			Http: &annotations.Http{Rules: tst.rules},
+		}
+		for _, api := range tst.apis {
+			v.serviceConfig.Apis = append(v.serviceConfig.Apis, &apipb.Api{Name: api})
+		}
+
+		v.validateServiceConfig()
+
+		if actual := v.resp.GetError(); actual != tst.want {
+			t.Errorf("%s: got(%s) want(%s)", tst.name, actual, tst.want)
+		}
+
+		// reset resp.Error field between tests
+		v.resp.Error = nil
+	}
+}
+
+func TestValidateGRPCServiceConfig(t *testing.T) {
+	var v validator
+
+	book := builder.NewMessage("Book")
+	rpc := builder.RpcTypeMessage
+	f, err := builder.NewFile("library.proto").SetPackageName("library").
+		AddMessage(book).
+		AddService(builder.NewService("Library").
+			AddMethod(builder.NewMethod("GetBook", rpc(book, false), rpc(book, false))).
+			AddMethod(builder.NewMethod("ListBooks", rpc(book, false), rpc(book, false)))).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"library.proto": f}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "library_grpc_service_config.json")
+	data := `{
+  "methodConfig": [{
+    "name": [
+      {"service": "library.Library", "method": "GetBook"},
+      {"service": "google.longrunning.Operations"}
+    ],
+    "timeout": "60s",
+    "retryPolicy": {
+      "maxAttempts": 5,
+      "initialBackoff": "0.100s",
+      "maxBackoff": "60s",
+      "backoffMultiplier": 1.3,
+      "retryableStatusCodes": ["UNAVAILABLE", 4]
+    }
+  }, {
+    "name": [
+      {"service": "library.Library", "method": "GetBook"},
+      {"service": "library.Library", "method": "DeleteBook"},
+      {"service": "library.Missing"},
+      {"method": "ListBooks"}
+    ],
+    "timeout": "60",
+    "retryPolicy": {
+      "initialBackoff": "0s",
+      "maxBackoff": "1m",
+      "backoffMultiplier": 0.5,
+      "retryableStatusCodes": ["OK", "NOT_A_CODE", 42]
+    }
+  }, {
+    "name": [{"service": "library.Library"}],
+    "retryPolicy": {
+      "initialBackoff": "1s",
+      "maxBackoff": "10s",
+      "backoffMultiplier": 2
+    }
+  }]
+}`
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.parseParameters("grpc-service-config=" + path); err != nil {
+		t.Fatal(err)
+	}
+
+	v.validateGRPCServiceConfig()
+
+	want := fmt.Sprintf("\n"+gscDuplicateName+"\n"+gscMethodDNE+"\n"+gscServiceDNE+"\n"+gscMissingService+
+		"\n"+gscInvalidDuration+"\n"+gscNonPositive+"\n"+gscInvalidDuration+"\n"+gscMultiplier+
+		"\n"+gscInvalidStatusCode+"\n"+gscInvalidStatusCode+"\n"+gscInvalidStatusCode+"\n"+gscNoStatusCodes,
+		0, 1, "library.Library/GetBook",
+		1, "DeleteBook", "library.Library",
+		1, "library.Missing",
+		1, "ListBooks",
+		1, "timeout", "60",
+		1, "initialBackoff", "0s",
+		1, "retryPolicy.maxBackoff", "1m",
+		1, 0.5,
+		1, "OK",
+		1, "NOT_A_CODE",
+		1, 42,
+		2,
+	)
+	if actual := v.resp.GetError(); actual != want {
+		t.Errorf("validateGRPCServiceConfig: got(%s) want(%s)", actual, want)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := ioutil.WriteFile(bad, []byte(`{"methodConfig": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.parseParameters("grpc-service-config=" + bad); err == nil {
+		t.Error("grpc-service-config: expected error for malformed config")
+	}
+}
+
+func TestValidatePublishing(t *testing.T) {
+	var v validator
+
+	lroDesc, err := desc.LoadMessageDescriptorForMessage(&longrunning.Operation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book := builder.NewMessage("Book")
+	req := builder.NewMessage("CreateBookRequest").
+		AddField(builder.NewField("request_id", builder.FieldTypeString()).SetOptions(fieldInfoOpts(t, annotations.FieldInfo_UUID4, annotations.FieldBehavior_OPTIONAL))).
+		AddField(builder.NewField("ip", builder.FieldTypeString()).SetOptions(fieldInfoOpts(t, annotations.FieldInfo_IPV4, annotations.FieldBehavior_OPTIONAL))).
+		AddField(builder.NewField("count", builder.FieldTypeInt32()))
+	rpc := builder.RpcTypeMessage
+	f, err := builder.NewFile("library.proto").SetPackageName("library.v1").
+		AddMessage(book).AddMessage(req).
+		AddService(builder.NewService("Library").
+			AddMethod(builder.NewMethod("CreateBook", rpc(req, false), rpc(book, false))).
+			AddMethod(builder.NewMethod("ImportBooks", rpc(req, false), builder.RpcTypeImportedMessage(lroDesc, false)))).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"library.proto": f}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "library_v1.yaml")
+	data := `type: google.api.Service
+config_version: 3
+name: library.googleapis.com
+
+publishing:
+  method_settings:
+  - selector: library.v1.Library.ImportBooks
+    long_running:
+      initial_poll_delay: 5s
+      poll_delay_multiplier: 1.5
+      max_poll_delay: 45s
+      total_poll_timeout: 300s
+    auto_populated_fields:
+    - request_id
+  - selector: library.v1.Library.CreateBook
+    long_running:
+      initial_poll_delay: 5s
+    auto_populated_fields:
+    - request_id
+    - ip
+    - count
+    - missing
+  - selector: library.v1.Library.DeleteBook
+  library_settings:
+  - version: library.v1
+    java_settings:
+      service_class_names:
+        library.v1.Library: LibraryClient
+        library.v1.Shelves: ShelvesClient
+    dotnet_settings:
+      renamed_services:
+        Library: LibraryService
+      renamed_resources:
+        cloudresourcemanager.googleapis.com/Project: ResourceManagerProject
+      ignored_resources:
+      - library.googleapis.com/Shelf
+    go_settings:
+      renamed_services:
+        Publisher: Pub
+  - version: library.v2
+`
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.parseParameters("service-yaml=" + path); err != nil {
+		t.Fatal(err)
+	}
+
+	v.validatePublishing(v.serviceConfig.GetPublishing())
+
+	want := fmt.Sprintf("\n"+svcPubLongRunningNotLRO+"\n"+svcPubAutoPopNotUUID4+"\n"+svcPubAutoPopNotString+"\n"+svcPubAutoPopNotUUID4+
+		"\n"+svcPubAutoPopDNE+"\n"+svcPubMethodDNE+"\n"+svcPubServiceDNE+"\n"+svcPubServiceDNE+"\n"+svcPubResourceDNE+"\n"+svcPubVersionDNE,
+		"library.v1.Library.CreateBook",
+		"library.v1.Library.CreateBook", "ip",
+		"library.v1.Library.CreateBook", "count",
+		"library.v1.Library.CreateBook", "count",
+		"library.v1.Library.CreateBook", "missing", "library.v1.CreateBookRequest",
+		"library.v1.Library.DeleteBook",
+		"java_settings.service_class_names", "library.v1.Shelves",
+		"go_settings.renamed_services", "Publisher",
+		"dotnet_settings.ignored_resources", "library.googleapis.com/Shelf",
+		"library.v2",
+	)
+	if actual := v.resp.GetError(); actual != want {
+		t.Errorf("validatePublishing: got(%s) want(%s)", actual, want)
+	}
+}
+
+func TestValidateSelectiveGeneration(t *testing.T) {
+	var v validator
+
+	lroDesc, err := desc.LoadMessageDescriptorForMessage(&longrunning.Operation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := builder.NewMessage("Msg")
+	rpc := builder.RpcTypeMessage
+	f, err := builder.NewFile("library.proto").SetPackageName("library").
+		AddMessage(msg).
+		AddService(builder.NewService("Library").
+			AddMethod(builder.NewMethod("CreateBook", rpc(msg, false), rpc(msg, false))).
+			AddMethod(builder.NewMethod("ImportBooks", rpc(msg, false), builder.RpcTypeImportedMessage(lroDesc, false)))).
+		AddService(builder.NewService("Instances").
+			AddMethod(builder.NewMethod("Insert", rpc(msg, false), rpc(msg, false)).
+				SetOptions(methodOpts(t, extendedops.E_OperationService, proto.String("ZoneOperations"))))).
+		AddService(builder.NewService("ZoneOperations").
+			AddMethod(builder.NewMethod("Get", rpc(msg, false), rpc(msg, false)).
+				SetOptions(methodOpts(t, extendedops.E_OperationPollingMethod, proto.Bool(true))))).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"library.proto": f}
+
+	selective := func(methods ...string) *annotations.CommonLanguageSettings {
+		return &annotations.CommonLanguageSettings{
+			SelectiveGapicGeneration: &annotations.SelectiveGapicGeneration{Methods: methods},
+		}
+	}
+
+	v.serviceConfig = &serviceconfig.Service{
+		Name: "library.googleapis.com",
+		Apis: []*apipb.Api{
+			{Name: "library.Library"},
+			{Name: "library.Instances"},
+			{Name: "library.ZoneOperations"},
+			{Name: "google.longrunning.Operations"},
+		},
+		Publishing: &annotations.Publishing{
+			LibrarySettings: []*annotations.ClientLibrarySettings{{
+				JavaSettings: &annotations.JavaSettings{Common: selective(
+					"library.Library.CreateBook",
+					"library.Library.ImportBooks",
+					"library.Library.Missing",
+					"library.Instances.Insert",
+					"google.longrunning.Operations.ListOperations",
+				)},
+				GoSettings: &annotations.GoSettings{Common: selective(
+					"library.Library.ImportBooks",
+					"library.Instances.Insert",
+					"library.ZoneOperations.Get",
+				)},
+				PythonSettings: &annotations.PythonSettings{Common: &annotations.CommonLanguageSettings{}},
+			}},
+		},
+	}
+
+	v.validateServiceConfig()
+
+	want := fmt.Sprintf("\n"+svcSelectiveMethodDNE+"\n"+svcSelectiveDangling+"\n"+svcSelectiveDangling+"\n"+svcSelectiveDangling,
+		"java_settings", "library.Library.Missing",
+		"java_settings", "library.Library.ImportBooks", "google.longrunning.Operations.GetOperation",
+		"java_settings", "library.Instances.Insert", "library.ZoneOperations.Get",
+		"go_settings", "library.Library.ImportBooks", "google.longrunning.Operations.GetOperation",
+	)
+	if actual := v.resp.GetError(); actual != want {
+		t.Errorf("validateSelectiveGeneration: got(%s) want(%s)", actual, want)
+	}
+
+	wantWarnings := []string{
+		fmt.Sprintf(svcSelectiveEmpty, "java_settings", "library.ZoneOperations"),
+	}
+	if fmt.Sprint(v.warnings) != fmt.Sprint(wantWarnings) {
+		t.Errorf("validateSelectiveGeneration warnings: got(%v) want(%v)", v.warnings, wantWarnings)
+	}
+}
+
+func TestValidateStandardMethods(t *testing.T) {
+	var v validator
+
+	refOpts := func(ref *annotations.ResourceReference) *descriptor.FieldOptions {
+		return fieldOpts(t, annotations.E_ResourceReference, ref)
+	}
+
+	sigOpts := func(sigs ...string) *descriptor.MethodOptions {
+		return methodOpts(t, annotations.E_MethodSignature, sigs)
+	}
+
+	maskDesc, err := desc.LoadMessageDescriptorForMessage(&fieldmaskpb.FieldMask{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	str := builder.FieldTypeString
+	shelf := builder.NewMessage("Shelf").SetOptions(resourceOpts(t, "library.googleapis.com/Shelf", "shelves/{shelf}")).
+		AddField(builder.NewField("name", str()))
+	book := builder.NewMessage("Book").SetOptions(resourceOpts(t, "library.googleapis.com/Book", "shelves/{shelf}/books/{book}")).
+		AddField(builder.NewField("name", str()))
+	bookRef := &annotations.ResourceReference{Type: "library.googleapis.com/Book"}
+
+	getBook := builder.NewMessage("GetBookRequest").
+		AddField(builder.NewField("name", str()).SetOptions(refOpts(bookRef)))
+	deleteBook := builder.NewMessage("DeleteBookRequest").
+		AddField(builder.NewField("name", str()))
+	listBooks := builder.NewMessage("ListBooksRequest").
+		AddField(builder.NewField("parent", str()).SetOptions(refOpts(bookRef)))
+	listShelves := builder.NewMessage("ListShelvesRequest")
+	createBook := builder.NewMessage("CreateBookRequest").
+		AddField(builder.NewField("book", builder.FieldTypeMessage(book)))
+	updateBook := builder.NewMessage("UpdateBookRequest").
+		AddField(builder.NewField("book", str())).
+		AddField(builder.NewField("update_mask", builder.FieldTypeImportedMessage(maskDesc)))
+	updateShelf := builder.NewMessage("UpdateShelfRequest").
+		AddField(builder.NewField("shelf", builder.FieldTypeMessage(shelf))).
+		AddField(builder.NewField("update_mask", builder.FieldTypeImportedMessage(maskDesc)))
+
+	rpc := builder.RpcTypeMessage
+	f, err := builder.NewFile("library.proto").SetPackageName("library").
+		AddMessage(shelf).AddMessage(book).
+		AddMessage(getBook).AddMessage(deleteBook).AddMessage(listBooks).AddMessage(listShelves).
+		AddMessage(createBook).AddMessage(updateBook).AddMessage(updateShelf).
+		AddService(builder.NewService("Library").
+			AddMethod(builder.NewMethod("GetBook", rpc(getBook, false), rpc(book, false))).
+			AddMethod(builder.NewMethod("DeleteBook", rpc(deleteBook, false), rpc(book, false))).
+			AddMethod(builder.NewMethod("ListBooks", rpc(listBooks, false), rpc(book, false))).
+			AddMethod(builder.NewMethod("ListShelves", rpc(listShelves, false), rpc(shelf, false))).
+			AddMethod(builder.NewMethod("CreateBook", rpc(createBook, false), rpc(book, false))).
+			AddMethod(builder.NewMethod("UpdateBook", rpc(updateBook, false), rpc(book, false))).
+			AddMethod(builder.NewMethod("UpdateShelf", rpc(updateShelf, false), rpc(shelf, false)).
+				SetOptions(sigOpts("shelf, update_mask")))).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.files = map[string]*desc.FileDescriptor{"library.proto": f}
+
+	for _, m := range f.FindService("library.Library").GetMethods() {
+		v.validateStandardMethod(m)
+	}
+
+	want := fmt.Sprintf("\n"+stdMissingRef+"\n"+stdMissingRef+"\n"+stdMissingField+"\n"+stdFieldType+"\n"+stdMissingSignature,
+		"library.Library.DeleteBook", "library.DeleteBookRequest.name", "type", "library.googleapis.com/Book",
+		"library.Library.ListBooks", "library.ListBooksRequest.parent", "child_type", "library.googleapis.com/Book",
+		"library.Library.CreateBook", "library.CreateBookRequest", "parent",
+		"library.Library.UpdateBook", "library.UpdateBookRequest.book", "library.Book", "string",
+		"library.Library.UpdateBook", "book,update_mask",
+	)
+	if actual := v.resp.GetError(); actual != want {
+		t.Errorf("validateStandardMethod: got(%s) want(%s)", actual, want)
+	}
+}
+
+func TestValidateBreaking(t *testing.T) {
+	var v validator
+
+	lroDesc, err := desc.LoadMessageDescriptorForMessage(&longrunning.Operation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type version struct {
+		host, resType, nameField, lroRes string
+		patterns, sigs                   []string
+		required                         bool
+	}
+
+	build := func(ver version) *desc.FileDescriptor {
+		servOpts := serviceOpts(t, annotations.E_DefaultHost, proto.String(ver.host))
+
+		resOpts := messageOpts(t, annotations.E_Resource, &annotations.ResourceDescriptor{
+			Type:      ver.resType,
+			Pattern:   ver.patterns,
+			NameField: ver.nameField,
+		})
+
+		rpcOpts := methodOpts(t, annotations.E_MethodSignature, ver.sigs)
+		setExt(t, rpcOpts, longrunning.E_OperationInfo, &longrunning.OperationInfo{
+			ResponseType: ver.lroRes,
+			MetadataType: "library.Book",
+		})
+
+		titleOpts := &descriptor.FieldOptions{}
+		if ver.required {
+			setExt(t, titleOpts, annotations.E_FieldBehavior, []annotations.FieldBehavior{annotations.FieldBehavior_REQUIRED})
+		}
+
+		book := builder.NewMessage("Book").SetOptions(resOpts).
+			AddField(builder.NewField("name", builder.FieldTypeString())).
+			AddField(builder.NewField("title", builder.FieldTypeString()).SetOptions(titleOpts))
+		shelf := builder.NewMessage("Shelf")
+
+		f, err := builder.NewFile("library.proto").SetPackageName("library").
+			AddMessage(book).AddMessage(shelf).
+			AddService(builder.NewService("Library").SetOptions(servOpts).
+				AddMethod(builder.NewMethod("ImportBooks", builder.RpcTypeMessage(book, false), builder.RpcTypeImportedMessage(lroDesc, false)).
+					SetOptions(rpcOpts))).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return f
+	}
+
+	before := build(version{
+		host:     "library.googleapis.com",
+		resType:  "library.googleapis.com/Book",
+		lroRes:   "Book",
+		patterns: []string{"shelves/{shelf}/books/{book}", "books/{book}"},
+		sigs:     []string{"name", "name,title", "title"},
+	})
+
+	// unchanged, modulo an equivalent operation_info type and a new signature
+	v.baseline = map[string]*desc.FileDescriptor{"library.proto": before}
+	after := build(version{
+		host:     "library.googleapis.com",
+		resType:  "library.googleapis.com/Book",
+		lroRes:   "library.Book",
+		patterns: []string{"shelves/{shelf}/books/{book}", "books/{book}"},
+		sigs:     []string{"name", "parent", "name,title", "title"},
+	})
+	// each version is a new file set, drop the index of the previous one
+	v.files = map[string]*desc.FileDescriptor{"library.proto": after}
+	v.idx = nil
+
+	v.validateBreaking(after)
+
+	if actual := v.resp.GetError(); actual != "" {
+		t.Errorf("validateBreaking(compatible): got(%s) want()", actual)
+	}
+
+	after = build(version{
+		host:      "library.example.com",
+		resType:   "library.googleapis.com/Book",
+		nameField: "title",
+		lroRes:    "Shelf",
+		patterns:  []string{"shelves/{shelf}/books/{book}"},
+		sigs:      []string{"title", "name"},
+		required:  true,
+	})
+	v.files = map[string]*desc.FileDescriptor{"library.proto": after}
+	v.idx = nil
+
+	v.validateBreaking(after)
+
+	want := fmt.Sprintf("\n"+brkDefaultHost+"\n"+brkSigRemoved+"\n"+brkSigReordered+"\n"+brkLROType+"\n"+brkRequired+"\n"+brkResPattern+"\n"+brkNameField,
+		"library.Library", "library.googleapis.com", "library.example.com",
+		"library.Library.ImportBooks", "name,title",
+		"library.Library.ImportBooks", "title", "name",
+		"library.Library.ImportBooks", "response_type", "library.Book", "library.Shelf",
+		"library.Book.title",
+		"library.googleapis.com/Book", "books/{book}",
+		"library.googleapis.com/Book", "name", "title",
+	)
+	if actual := v.resp.GetError(); actual != want {
+		t.Errorf("validateBreaking: got(%s) want(%s)", actual, want)
+	}
+	v.resp.Error = nil
+
+	// changing the resource type is only reported for the resource message
+	after = build(version{
+		host:     "library.googleapis.com",
+		resType:  "library.googleapis.com/Novel",
+		lroRes:   "Book",
+		patterns: []string{"shelves/{shelf}/books/{book}", "books/{book}"},
+		sigs:     []string{"name", "name,title", "title"},
+	})
+	v.files = map[string]*desc.FileDescriptor{"library.proto": after}
+	v.idx = nil
+
+	v.validateBreaking(after)
+
+	want = fmt.Sprintf("\n"+brkResType, "library.Book", "library.googleapis.com/Book", "library.googleapis.com/Novel")
+	if actual := v.resp.GetError(); actual != want {
+		t.Errorf("validateBreaking(type): got(%s) want(%s)", actual, want)
+	}
+}
+
+func TestLoadBaseline(t *testing.T) {
+	f, err := builder.NewFile("library.proto").SetPackageName("library").
+		AddMessage(builder.NewMessage("Book")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := proto.Marshal(&descriptor.FileDescriptorSet{File: []*descriptor.FileDescriptorProto{f.AsFileDescriptorProto()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "baseline.pb")
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := loadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if files["library.proto"].FindMessage("library.Book") == nil {
+		t.Errorf("loadBaseline: missing library.Book in %v", files)
+	}
+
+	if _, err := loadBaseline(filepath.Join(t.TempDir(), "missing.pb")); err == nil {
+		t.Error("loadBaseline: want error for missing file")
+	}
+}
+
+func TestFilesToValidate(t *testing.T) {
+	fdp := func(name string, deps ...string) *descriptor.FileDescriptorProto {
+		return &descriptor.FileDescriptorProto{Name: proto.String(name), Dependency: deps}
+	}
+
+	protos := []*descriptor.FileDescriptorProto{
+		fdp("google/api/resource.proto"),
+		fdp("library/v1/resources.proto", "google/api/resource.proto"),
+		fdp("library/v1/library.proto", "library/v1/resources.proto"),
+		fdp("library/v1/admin.proto", "library/v1/library.proto"),
+		fdp("library/v1/other.proto"),
+	}
+	gen := []string{"library/v1/resources.proto", "library/v1/library.proto", "library/v1/admin.proto", "library/v1/other.proto"}
+
+	for _, tst := range []struct {
+		name    string
+		changed []string
+		want    []string
+	}{
+		{"leaf", []string{"protos/library/v1/admin.proto"}, []string{"library/v1/admin.proto"}},
+		{"reverse deps", []string{"protos/library/v1/resources.proto", "README.md"}, []string{"library/v1/resources.proto", "library/v1/library.proto", "library/v1/admin.proto"}},
+		{"dependency not generated", []string{"google/api/resource.proto"}, []string{"library/v1/resources.proto", "library/v1/library.proto", "library/v1/admin.proto"}},
+		{"partial name", []string{"v1/other.proto"}, nil},
+		{"nothing", []string{}, nil},
+	} {
+		if got := filesToValidate(protos, gen, tst.changed); fmt.Sprint(got) != fmt.Sprint(tst.want) {
+			t.Errorf("filesToValidate(%s): got(%v) want(%v)", tst.name, got, tst.want)
+		}
+	}
+}
+
+func TestReadChangedFiles(t *testing.T) {
+	got, err := ReadChangedFiles(strings.NewReader("a/b.proto\n\n  c.proto \nREADME.md\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"a/b.proto", "c.proto", "README.md"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ReadChangedFiles: got(%v) want(%v)", got, want)
+	}
+
+	if got, err := ReadChangedFiles(strings.NewReader("")); err != nil || got == nil {
+		t.Errorf("ReadChangedFiles(empty): got(%v, %v) want([], nil)", got, err)
+	}
+}
+
+// setExt sets the extension e to val on the given options.
+func setExt(t *testing.T, opts proto.Message, e *proto.ExtensionDesc, val interface{}) {
+	t.Helper()
+
+	if err := proto.SetExtension(opts, e, val); err != nil {
+		t.Error(err)
+	}
+}
+
+// fieldOpts returns FieldOptions with the extension e set to val.
+func fieldOpts(t *testing.T, e *proto.ExtensionDesc, val interface{}) *descriptor.FieldOptions {
+	t.Helper()
+
+	opts := &descriptor.FieldOptions{}
+	setExt(t, opts, e, val)
+	return opts
+}
+
+// messageOpts returns MessageOptions with the extension e set to val.
+func messageOpts(t *testing.T, e *proto.ExtensionDesc, val interface{}) *descriptor.MessageOptions {
+	t.Helper()
+
+	opts := &descriptor.MessageOptions{}
+	setExt(t, opts, e, val)
+	return opts
+}
+
+// fieldInfoOpts returns FieldOptions with a google.api.field_info of the
+// given format and, if any, the given google.api.field_behavior.
+func fieldInfoOpts(t *testing.T, format annotations.FieldInfo_Format, behavior ...annotations.FieldBehavior) *descriptor.FieldOptions {
+	t.Helper()
+
+	opts := fieldOpts(t, annotations.E_FieldInfo, &annotations.FieldInfo{Format: format})
+	if len(behavior) > 0 {
+		setExt(t, opts, annotations.E_FieldBehavior, behavior)
+	}
+	return opts
+}
+
+// resourceOpts returns MessageOptions with a google.api.resource of the
+// given type and patterns.
+func resourceOpts(t *testing.T, typ string, pats ...string) *descriptor.MessageOptions {
+	t.Helper()
+
+	return messageOpts(t, annotations.E_Resource, &annotations.ResourceDescriptor{Type: typ, Pattern: pats})
+}
+
+// methodOpts returns MethodOptions with the extension e set to val.
+func methodOpts(t *testing.T, e *proto.ExtensionDesc, val interface{}) *descriptor.MethodOptions {
+	t.Helper()
+
+	opts := &descriptor.MethodOptions{}
+	setExt(t, opts, e, val)
+	return opts
+}
+
+// serviceOpts returns ServiceOptions with the extension e set to val.
+func serviceOpts(t *testing.T, e *proto.ExtensionDesc, val interface{}) *descriptor.ServiceOptions {
+	t.Helper()
+
+	opts := &descriptor.ServiceOptions{}
+	setExt(t, opts, e, val)
+	return opts
+}
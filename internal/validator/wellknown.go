@@ -0,0 +1,187 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+var (
+	// wellKnownCatalog is the built-in list of well-known resources.
+	//
+	//go:embed wellknown.yaml
+	wellKnownCatalog []byte
+
+	// wellKnown indexes the built-in well-known resources, shared by every
+	// validation run.
+	wellKnown wellKnownSet
+)
+
+func init() {
+	resources, err := parseWellKnownResources(wellKnownCatalog, ".yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, res := range resources {
+		wellKnown.add(res)
+	}
+}
+
+// wellKnownSet indexes well-known resources by type, pattern and, for each
+// pattern, the name of its final variable.
+type wellKnownSet struct {
+	resources map[string]*annotations.ResourceDescriptor
+	patterns  map[string]bool
+	names     map[string]bool
+}
+
+// add merges the resource into the set.
+func (s *wellKnownSet) add(res *annotations.ResourceDescriptor) {
+	if s.resources == nil {
+		s.resources = map[string]*annotations.ResourceDescriptor{}
+		s.patterns = map[string]bool{}
+		s.names = map[string]bool{}
+	}
+
+	s.resources[res.GetType()] = res
+
+	for _, pat := range res.GetPattern() {
+		s.patterns[pat] = true
+
+		if p, err := parsePattern(pat); err == nil && p.lastVariable() != "" {
+			s.names[p.lastVariable()] = true
+		}
+	}
+}
+
+// loadWellKnownResources reads a list of additional well-known resources
+// from the file at path and adds them to the validator's own well-known set,
+// consulted alongside the built-in catalog for resolution and comparison.
+//
+// Files ending in .textproto, .txtpb or .pbtxt are parsed as text format
+// google.protobuf.FileOptions containing google.api.resource_definition
+// entries, exactly as they would be written in a .proto file:
+//
+//	[google.api.resource_definition] {
+//	  type: "iam.example.com/ServiceAccount"
+//	  pattern: "projects/{project}/serviceAccounts/{service_account}"
+//	}
+//
+// Any other file is parsed as YAML with a top-level resources list:
+//
+//	resources:
+//	- type: iam.example.com/ServiceAccount
+//	  pattern:
+//	  - projects/{project}/serviceAccounts/{service_account}
+func (v *validator) loadWellKnownResources(path string) error {
+	f, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading well-known resources: %v", err)
+	}
+
+	resources, err := parseWellKnownResources(f, filepath.Ext(path))
+	if err != nil {
+		return err
+	}
+
+	for _, res := range resources {
+		v.extraWellKnown.add(res)
+	}
+
+	return nil
+}
+
+// parseWellKnownResources decodes a list of resources in the format
+// indicated by the file extension format, see loadWellKnownResources.
+func parseWellKnownResources(f []byte, format string) ([]*annotations.ResourceDescriptor, error) {
+	var resources []*annotations.ResourceDescriptor
+	switch format {
+	case ".textproto", ".txtpb", ".pbtxt":
+		opts := &descriptor.FileOptions{}
+		if err := prototext.Unmarshal(f, opts); err != nil {
+			return nil, fmt.Errorf("error decoding well-known resources: %v", err)
+		}
+
+		if eResDef, err := ext(opts, annotations.E_ResourceDefinition); err == nil {
+			resources = eResDef.([]*annotations.ResourceDescriptor)
+		}
+	default:
+		j, err := yaml.YAMLToJSON(f)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding well-known resources: %v", err)
+		}
+
+		var list struct {
+			Resources []json.RawMessage `json:"resources"`
+		}
+		if err := json.Unmarshal(j, &list); err != nil {
+			return nil, fmt.Errorf("error decoding well-known resources: %v", err)
+		}
+
+		for _, raw := range list.Resources {
+			res := &annotations.ResourceDescriptor{}
+			if err := jsonpb.Unmarshal(bytes.NewReader(raw), res); err != nil {
+				return nil, fmt.Errorf("error decoding well-known resources: %v", err)
+			}
+			resources = append(resources, res)
+		}
+	}
+
+	for _, res := range resources {
+		if res.GetType() == "" {
+			return nil, fmt.Errorf("error decoding well-known resources: resource missing type")
+		}
+	}
+
+	return resources, nil
+}
+
+// wellKnownResource returns the well-known resource with the given type,
+// either built-in or loaded for this run, or nil.
+func (v *validator) wellKnownResource(typ string) *annotations.ResourceDescriptor {
+	if res := wellKnown.resources[typ]; res != nil {
+		return res
+	}
+
+	return v.extraWellKnown.resources[typ]
+}
+
+// isWellKnownType reports if typ is a well-known resource type.
+func (v *validator) isWellKnownType(typ string) bool {
+	return v.wellKnownResource(typ) != nil
+}
+
+// isWellKnownPattern reports if pat is a pattern of a well-known resource.
+func (v *validator) isWellKnownPattern(pat string) bool {
+	return wellKnown.patterns[pat] || v.extraWellKnown.patterns[pat]
+}
+
+// isWellKnownName reports if name is the final pattern variable of a
+// well-known resource.
+func (v *validator) isWellKnownName(name string) bool {
+	return wellKnown.names[name] || v.extraWellKnown.names[name]
+}
@@ -14,13 +14,26 @@
 
 load("@com_google_api_codegen//rules_gapic:gapic.bzl", "proto_custom_library")
 
-def gapic_config_validation(name, srcs, gapic_yaml = None, **kwargs):
+def gapic_config_validation(name, srcs, gapic_yaml = None, service_yaml = None, grpc_service_config = None, well_known_resources = None, baseline = None, changed_files = None, **kwargs):
   file_args = {}
 
   if gapic_yaml:
-    file_args = {
-      gapic_yaml: "gapic-yaml",
-    }
+    file_args[gapic_yaml] = "gapic-yaml"
+
+  if service_yaml:
+    file_args[service_yaml] = "service-yaml"
+
+  if grpc_service_config:
+    file_args[grpc_service_config] = "grpc-service-config"
+
+  if well_known_resources:
+    file_args[well_known_resources] = "well-known-resources"
+
+  if baseline:
+    file_args[baseline] = "baseline"
+
+  if changed_files:
+    file_args[changed_files] = "changed-files"
 
   proto_custom_library(
     name = name,